@@ -24,9 +24,15 @@ SOFTWARE.
 package main
 
 import (
+	"errors"
+	"io"
 	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // fsHandler implements the fileHandler interface defined in the process package
@@ -36,6 +42,20 @@ func (f *fsHandler) ReadFile(name string) ([]byte, error) {
 	return os.ReadFile(name)
 }
 
+func (f *fsHandler) ReadPrefix(name string, n int) ([]byte, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data := make([]byte, n)
+	read, err := io.ReadFull(file, data)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return data[:read], nil
+}
+
 func (f *fsHandler) WalkDir(path string, fn fs.WalkDirFunc) error {
 	return filepath.WalkDir(path, fn)
 }
@@ -43,3 +63,29 @@ func (f *fsHandler) WalkDir(path string, fn fs.WalkDirFunc) error {
 func (f *fsHandler) WriteFile(name string, content []byte) error {
 	return os.WriteFile(name, content, 0)
 }
+
+func (f *fsHandler) ModTime(name string) (time.Time, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+func (f *fsHandler) CommitTime(name string) (time.Time, error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%ct", "--", filepath.Base(name))
+	cmd.Dir = filepath.Dir(name)
+	output, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, err
+	}
+	timestamp := strings.TrimSpace(string(output))
+	if len(timestamp) == 0 {
+		return time.Time{}, errors.New("the file has not been committed yet")
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
+}
@@ -34,11 +34,22 @@ import (
 
 var version = "development"
 
+// Exit codes
+const (
+	// exitOk means that all the files passed the check (or check mode was not enabled)
+	exitOk = 0
+	// exitCheckFailed means that at least one file ended up in one of the -fail-on actions
+	exitCheckFailed = 1
+	// exitError means that the files could not be processed or there were errors with some of them
+	exitError = 2
+)
+
 func main() {
 
 	opts, err := options.Parse(os.Args)
 	if err != nil {
-		log.Fatalf("could not parse the cli args: %s", err.Error())
+		log.Printf("could not parse the cli args: %s", err.Error())
+		os.Exit(exitError)
 	}
 
 	if opts.ShowVersion {
@@ -48,8 +59,26 @@ func main() {
 
 	stats, err := process.Files(opts.Process, new(fsHandler))
 	if err != nil {
-		log.Fatalf("could not process the files: %s", err.Error())
+		log.Printf("could not process the files: %s", err.Error())
+		os.Exit(exitError)
 	}
 
 	printStats(opts, stats)
+
+	os.Exit(exitCode(opts, stats))
+}
+
+// exitCode returns the exit code of the app according to the result of the processing. Outside
+// check mode, the app always exits with exitOk once the files have been processed.
+func exitCode(opts *options.Options, stats *process.Stats) int {
+	if !opts.Check {
+		return exitOk
+	}
+	if stats.Count(process.OperationError) > 0 {
+		return exitError
+	}
+	if stats.Count(opts.FailOn...) > 0 {
+		return exitCheckFailed
+	}
+	return exitOk
 }
@@ -42,27 +42,65 @@ var (
 // printStats writes to the standard output the result of the processing according
 // to the verbosity level
 func printStats(options *options.Options, stats *process.Stats) {
+	if options.JSON {
+		printJSON(options, stats)
+		return
+	}
 	if options.Verbose {
 		printFileOperations(stats)
+		printIgnored(stats)
 		printOptions(options)
 		printTotals(stats)
 	} else {
 		printShort(stats)
 	}
+	printMappings(stats)
 	printWarnings(stats)
+	if options.Check {
+		printCheck(options, stats)
+	}
 }
 
 // printFileOperations prints the files processed by operation type
 func printFileOperations(stats *process.Stats) {
 	fmt.Printf("files:\n")
-	printFiles(stats.Files[process.LicenseOk], "license_ok", okRender)
-	printFiles(stats.Files[process.LicenseReplaced], "license_replaced", warningRender)
+	printFiles(withScores(withLicenses(stats.Files[process.LicenseOk], stats.Licenses), stats.Scores), "license_ok", okRender)
+	printFiles(withScores(stats.Files[process.LicenseReplaced], stats.Scores), "license_replaced", warningRender)
 	printFiles(stats.Files[process.LicenseAdded], "license_added", errorRender)
+	printFiles(stats.Files[process.SkippedGenerated], "skipped_generated", okRender)
+	printFiles(withReasons(stats.Files[process.Exempted], stats.Reasons), "exempted", okRender)
+	printFiles(stats.Files[process.NoHeader], "no_header", okRender)
+	printFiles(stats.Files[process.YearUpdated], "year_updated", warningRender)
+	printFiles(stats.Files[process.LicenseMoved], "license_moved", warningRender)
+	printFiles(withScores(stats.Files[process.LicenseMerged], stats.Scores), "license_merged", warningRender)
+	printFiles(stats.Files[process.LicenseRemoved], "license_removed", warningRender)
+	printFiles(stats.Files[process.DuplicateFixed], "duplicate_fixed", warningRender)
 	printFiles(stats.Files[process.SkippedAdd], "skipped_add", errorRender)
-	printFiles(stats.Files[process.SkippedReplace], "skipped_replace", errorRender)
+	printFiles(withScores(stats.Files[process.SkippedReplace], stats.Scores), "skipped_replace", errorRender)
+	printFiles(stats.Files[process.YearOutdated], "year_outdated", errorRender)
+	printFiles(stats.Files[process.Misplaced], "misplaced", errorRender)
+	printFiles(withScores(stats.Files[process.ForeignLicense], stats.Scores), "foreign_license", errorRender)
+	printFiles(stats.Files[process.DuplicateHeader], "duplicate_header", errorRender)
+	printFiles(stats.Files[process.SkippedRemove], "skipped_remove", errorRender)
 	printFiles(stats.Files[process.OperationError], "errors", errorRender)
 }
 
+// printIgnored prints the paths excluded by the ignore files along with the file that excluded them (if any)
+func printIgnored(stats *process.Stats) {
+	if len(stats.Ignored) == 0 {
+		return
+	}
+	fmt.Printf("ignored:\n")
+	paths := make([]string, 0, len(stats.Ignored))
+	for path := range stats.Ignored {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Printf("  - %s (%s)\n", infoRender(path), stats.Ignored[path])
+	}
+}
+
 // printOptions prints the options that were supplied to the app
 func printOptions(options *options.Options) {
 	fmt.Printf("options:\n")
@@ -73,9 +111,21 @@ func printOptions(options *options.Options) {
 			fmt.Printf("    - %s\n", infoRender(fmt.Sprintf("%v", ignorePaths)))
 		}
 	}
-	fmt.Printf("  extensions:\n")
-	for _, ext := range options.Process.Extensions {
-		fmt.Printf("    - %s\n", infoRender(fmt.Sprintf("%v", ext)))
+	if len(options.Process.Extensions) > 0 {
+		fmt.Printf("  extensions:\n")
+		for _, ext := range options.Process.Extensions {
+			fmt.Printf("    - %s\n", infoRender(fmt.Sprintf("%v", ext)))
+		}
+	}
+	if len(options.Process.Includes) > 0 {
+		fmt.Printf("  includes:\n")
+		for _, include := range options.Process.Includes {
+			if len(include.Language) > 0 {
+				fmt.Printf("    - %s (%s)\n", infoRender(include.Pattern), include.Language)
+			} else {
+				fmt.Printf("    - %s\n", infoRender(include.Pattern))
+			}
+		}
 	}
 	fmt.Printf("  flags:\n")
 	if options.Process.Add {
@@ -84,10 +134,101 @@ func printOptions(options *options.Options) {
 	if options.Process.Replace {
 		fmt.Printf("    - %s\n", infoRender("replace"))
 	}
+	if options.Process.Remove {
+		fmt.Printf("    - %s\n", infoRender("remove"))
+	}
+	if options.Process.RemoveAny {
+		fmt.Printf("    - %s\n", infoRender("remove_any"))
+	}
+	if options.Process.UpdateYear {
+		fmt.Printf("    - %s\n", infoRender("update_year"))
+	}
+	if options.Process.Gitignore {
+		fmt.Printf("    - %s\n", infoRender("gitignore"))
+	}
+	if options.Process.YearFromGit {
+		fmt.Printf("    - %s\n", infoRender("year_from_git"))
+	}
+	if options.Process.MappingsOnly {
+		fmt.Printf("    - %s\n", infoRender("map_only"))
+	}
+	if options.Process.GeneratedMarkers != nil && len(options.Process.GeneratedMarkers) == 0 {
+		fmt.Printf("    - %s\n", infoRender("include_generated"))
+	}
 	if options.Verbose {
 		fmt.Printf("    - %s\n", infoRender("verbose"))
 	}
-	fmt.Printf("  license_header: %s\n", infoRender("%s", options.Process.LicensePath))
+	if len(options.Process.AllowedLicensePaths) > 0 || len(options.Process.AllowedSPDX) > 0 {
+		fmt.Printf("  allowed_licenses:\n")
+		for _, license := range append(append([]string{}, options.Process.AllowedLicensePaths...), options.Process.AllowedSPDX...) {
+			fmt.Printf("    - %s\n", infoRender(license))
+		}
+	}
+	if len(options.Process.SPDX) > 0 {
+		fmt.Printf("  spdx: %s\n", infoRender(options.Process.SPDX))
+	} else if len(options.Process.LicensePath) > 0 {
+		fmt.Printf("  license_header: %s\n", infoRender("%s", options.Process.LicensePath))
+	}
+	if normalize := options.Process.Normalize; len(normalize) != 1 || normalize[0] != process.NormalizeLineEndings {
+		fmt.Printf("  normalize:\n")
+		for _, level := range normalize {
+			fmt.Printf("    - %s\n", infoRender(level))
+		}
+	}
+	if len(options.Process.Mappings) > 0 {
+		fmt.Printf("  mappings:\n")
+		for _, mapping := range options.Process.Mappings {
+			fmt.Printf("    - %s\n", infoRender(mapping.Name))
+		}
+	}
+	if len(options.Process.MergeCopyrights) > 0 {
+		fmt.Printf("  merge_copyrights: %s\n", infoRender(options.Process.MergeCopyrights))
+	}
+	if len(options.Process.Keywords) > 0 {
+		fmt.Printf("  keywords:\n")
+		for _, keyword := range options.Process.Keywords {
+			fmt.Printf("    - %s\n", infoRender(keyword))
+		}
+	}
+	if len(options.Process.HeaderRules) > 0 {
+		fmt.Printf("  header_rules:\n")
+		for _, rule := range options.Process.HeaderRules {
+			fmt.Printf("    - %s\n", infoRender(rule.String()))
+		}
+	}
+	if len(options.Process.NotHeaderRules) > 0 {
+		fmt.Printf("  not_header_rules:\n")
+		for _, rule := range options.Process.NotHeaderRules {
+			fmt.Printf("    - %s\n", infoRender(rule.String()))
+		}
+	}
+	if len(options.Process.GeneratedMarkers) > 0 {
+		fmt.Printf("  generated_markers:\n")
+		for _, marker := range options.Process.GeneratedMarkers {
+			fmt.Printf("    - %s\n", infoRender(marker.String()))
+		}
+	}
+	if options.Process.OkSimilarity > 0 {
+		fmt.Printf("  ok_similarity: %s\n", infoRender(fmt.Sprintf("%.2f", options.Process.OkSimilarity)))
+	}
+	if options.Process.ForeignSimilarity > 0 {
+		fmt.Printf("  foreign_similarity: %s\n", infoRender(fmt.Sprintf("%.2f", options.Process.ForeignSimilarity)))
+	}
+	if options.Process.Spacing != nil && *options.Process.Spacing != process.DefaultSpacing {
+		fmt.Printf("  blank_lines: %s\n", infoRender(fmt.Sprintf("%d", options.Process.Spacing.After)))
+		fmt.Printf("  preamble_blank_lines: %s\n", infoRender(fmt.Sprintf("%d", options.Process.Spacing.Before)))
+	}
+	if len(options.Process.Variables) > 0 {
+		fmt.Printf("  variables:\n")
+		names := make([]string, 0, len(options.Process.Variables))
+		for name := range options.Process.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("    %s: %s\n", name, infoRender(options.Process.Variables[name]))
+		}
+	}
 }
 
 // printTotals prints the total amount of files processed by operation type
@@ -96,18 +237,65 @@ func printTotals(stats *process.Stats) {
 	printFileTotals(len(stats.Files[process.LicenseOk]), "license_ok", okRender)
 	printFileTotals(len(stats.Files[process.LicenseReplaced]), "license_replaced", warningRender)
 	printFileTotals(len(stats.Files[process.LicenseAdded]), "license_added", errorRender)
+	printFileTotals(len(stats.Files[process.SkippedGenerated]), "skipped_generated", okRender)
+	printFileTotals(len(stats.Files[process.Exempted]), "exempted", okRender)
+	printFileTotals(len(stats.Files[process.NoHeader]), "no_header", okRender)
+	printFileTotals(len(stats.Files[process.YearUpdated]), "year_updated", warningRender)
+	printFileTotals(len(stats.Files[process.LicenseMoved]), "license_moved", warningRender)
+	printFileTotals(len(stats.Files[process.LicenseMerged]), "license_merged", warningRender)
+	printFileTotals(len(stats.Files[process.LicenseRemoved]), "license_removed", warningRender)
+	printFileTotals(len(stats.Files[process.DuplicateFixed]), "duplicate_fixed", warningRender)
 	printFileTotals(len(stats.Files[process.SkippedAdd]), "skipped_add", errorRender)
 	printFileTotals(len(stats.Files[process.SkippedReplace]), "skipped_replace", errorRender)
+	printFileTotals(len(stats.Files[process.YearOutdated]), "year_outdated", errorRender)
+	printFileTotals(len(stats.Files[process.Misplaced]), "misplaced", errorRender)
+	printFileTotals(len(stats.Files[process.ForeignLicense]), "foreign_license", errorRender)
+	printFileTotals(len(stats.Files[process.DuplicateHeader]), "duplicate_header", errorRender)
+	printFileTotals(len(stats.Files[process.SkippedRemove]), "skipped_remove", errorRender)
 	printFileTotals(len(stats.Files[process.OperationError]), "error", errorRender)
 	fmt.Printf("  elapsed_time: %s\n", infoRender(fmt.Sprintf("%vms", stats.ElapsedMs)))
 }
 
+// printMappings prints the number of files whose header matched each mapping (if any)
+func printMappings(stats *process.Stats) {
+	if len(stats.Mappings) == 0 {
+		return
+	}
+	fmt.Printf("mappings:\n")
+	names := make([]string, 0, len(stats.Mappings))
+	for name := range stats.Mappings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		printFileTotals(len(stats.Mappings[name]), name, warningRender)
+	}
+}
+
 // printShort prints the result of the processing in a compact mode (non-verbose)
 func printShort(stats *process.Stats) {
-	fmt.Printf("%s licenses ok, %s licenses replaced, %s licenses added\n",
+	fmt.Printf("%s licenses ok, %s licenses replaced, %s licenses added",
 		okRender(fmt.Sprintf("%d", len(stats.Files[process.LicenseOk]))),
 		warningRender(fmt.Sprintf("%d", len(stats.Files[process.LicenseReplaced]))),
 		errorRender(fmt.Sprintf("%d", len(stats.Files[process.LicenseAdded]))))
+	printShortCount(len(stats.Files[process.LicenseMerged]), "licenses merged", warningRender)
+	printShortCount(len(stats.Files[process.LicenseMoved]), "licenses moved", warningRender)
+	printShortCount(len(stats.Files[process.YearUpdated]), "years updated", warningRender)
+	printShortCount(len(stats.Files[process.DuplicateFixed]), "duplicates fixed", warningRender)
+	printShortCount(len(stats.Files[process.LicenseRemoved]), "licenses removed", warningRender)
+	printShortCount(len(stats.Files[process.Misplaced]), "licenses misplaced", errorRender)
+	printShortCount(len(stats.Files[process.SkippedRemove]), "licenses not removed", errorRender)
+	printShortCount(len(stats.Files[process.NoHeader]), "files without header", okRender)
+	printShortCount(len(stats.Files[process.SkippedGenerated]), "generated files skipped", okRender)
+	printShortCount(len(stats.Files[process.Exempted]), "files exempted", okRender)
+	fmt.Printf("\n")
+}
+
+// printShortCount prints the number of files of one of the optional actions of the compact mode (if any)
+func printShortCount(count int, description string, render func(a ...interface{}) string) {
+	if count > 0 {
+		fmt.Printf(", %s %s", render(fmt.Sprintf("%d", count)), description)
+	}
 }
 
 // printWarnings warns the user if the -a or -r flag were not provided
@@ -116,14 +304,81 @@ func printWarnings(stats *process.Stats) {
 	if skippedAdds := len(stats.Files[process.SkippedAdd]); skippedAdds > 0 {
 		color.Error.Printf("[!] %d files had no license but were not changed as the -a (add) option was not supplied.\n", skippedAdds)
 	}
-	if skippedReplaces := len(stats.Files[process.SkippedReplace]); skippedReplaces > 0 {
+	notMapped := 0
+	for _, file := range stats.Files[process.SkippedReplace] {
+		if stats.Reasons[file] == process.NotMappedReason {
+			notMapped++
+		}
+	}
+	if skippedReplaces := len(stats.Files[process.SkippedReplace]) - notMapped; skippedReplaces > 0 {
 		color.Error.Printf("[!] %d files had a different license but were not changed as the -r (replace) option was not supplied.\n", skippedReplaces)
 	}
+	if notMapped > 0 {
+		color.Error.Printf("[!] %d files had a different license but were not changed as it did not match any mapping and the -map-only option was supplied.\n", notMapped)
+	}
+	if yearsOutdated := len(stats.Files[process.YearOutdated]); yearsOutdated > 0 {
+		color.Error.Printf("[!] %d files had an outdated copyright year but were not changed as the -r (replace) option was not supplied.\n", yearsOutdated)
+	}
+	if misplaced := len(stats.Files[process.Misplaced]); misplaced > 0 {
+		color.Error.Printf("[!] %d files had the license as a comment outside of their leading comments, which can be moved to the top of the files with the -r (replace) option.\n", misplaced)
+	}
+	if foreign := len(stats.Files[process.ForeignLicense]); foreign > 0 {
+		color.Error.Printf("[!] %d files had a license too different from the target one to be replaced.\n", foreign)
+	}
+	if duplicates := len(stats.Files[process.DuplicateHeader]); duplicates > 0 {
+		color.Error.Printf("[!] %d files had duplicated license headers but were not fixed as the -r (replace) option was not supplied.\n", duplicates)
+	}
+	if skippedRemoves := len(stats.Files[process.SkippedRemove]); skippedRemoves > 0 {
+		color.Error.Printf("[!] %d files had a license header to remove but were not changed as the -r (replace) option was not supplied.\n", skippedRemoves)
+	}
 	if errors := len(stats.Files[process.OperationError]); errors > 0 {
 		color.Error.Printf("[!] There where %d errors.\n", errors)
 	}
 }
 
+// printCheck tells the user if the check failed and which actions made it fail
+func printCheck(options *options.Options, stats *process.Stats) {
+	if failures := stats.Count(options.FailOn...); failures > 0 {
+		color.Error.Printf("[!] Check failed: %d files ended up in one of the following actions: %s.\n", failures, options.FailOn)
+	}
+}
+
+// withLicenses returns the files followed by the name of the allowed license found in them (if any)
+func withLicenses(files []string, licenses map[string]string) []string {
+	res := make([]string, len(files))
+	for i, file := range files {
+		res[i] = file
+		if license, ok := licenses[file]; ok {
+			res[i] = fmt.Sprintf("%s (%s)", file, license)
+		}
+	}
+	return res
+}
+
+// withScores returns the files followed by the similarity of their header with the target license (if any)
+func withScores(files []string, scores map[string]float64) []string {
+	res := make([]string, len(files))
+	for i, file := range files {
+		res[i] = file
+		if score, ok := scores[file]; ok {
+			res[i] = fmt.Sprintf("%s (score %.2f)", file, score)
+		}
+	}
+	return res
+}
+
+// withReasons returns the files followed by the reason given by their directive (if any)
+func withReasons(files []string, reasons map[string]string) []string {
+	res := make([]string, len(files))
+	for i, file := range files {
+		res[i] = file
+		if reason, ok := reasons[file]; ok {
+			res[i] = fmt.Sprintf("%s (%s)", file, reason)
+		}
+	}
+	return res
+}
+
 func printFiles(files []string, operationName string, render func(a ...interface{}) string) {
 	if len(files) <= 0 {
 		return
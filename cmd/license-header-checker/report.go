@@ -0,0 +1,114 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/lluissm/license-header-checker/internal/options"
+	"github.com/lluissm/license-header-checker/pkg/process"
+)
+
+// report is the result of the processing printed with the -json option
+type report struct {
+	Files       []reportFile      `json:"files"`
+	Totals      map[string]int    `json:"totals"`
+	Mappings    map[string]int    `json:"mappings,omitempty"`
+	Ignored     map[string]string `json:"ignored,omitempty"`
+	CheckFailed *bool             `json:"check_failed,omitempty"`
+	ElapsedMs   int64             `json:"elapsed_ms"`
+}
+
+// reportFile is the result of processing one file
+type reportFile struct {
+	Path    string   `json:"path"`
+	Action  string   `json:"action"`
+	License string   `json:"license,omitempty"`
+	Mapping string   `json:"mapping,omitempty"`
+	Score   *float64 `json:"score,omitempty"`
+	Reason  string   `json:"reason,omitempty"`
+}
+
+// newReport returns the report of the processing sorting the files by path
+func newReport(options *options.Options, stats *process.Stats) *report {
+	r := &report{Files: []reportFile{}, Totals: make(map[string]int), ElapsedMs: stats.ElapsedMs}
+	if len(stats.Ignored) > 0 {
+		r.Ignored = stats.Ignored
+	}
+
+	mappings := make(map[string]string)
+	for name, files := range stats.Mappings {
+		for _, file := range files {
+			mappings[file] = name
+		}
+		if r.Mappings == nil {
+			r.Mappings = make(map[string]int)
+		}
+		r.Mappings[name] = len(files)
+	}
+
+	scores := make(map[string]*float64)
+	for file := range stats.Scores {
+		score := stats.Scores[file]
+		scores[file] = &score
+	}
+
+	for action, files := range stats.Files {
+		if len(files) == 0 {
+			continue
+		}
+		r.Totals[action.String()] = len(files)
+		for _, file := range files {
+			r.Files = append(r.Files, reportFile{
+				Path:    file,
+				Action:  action.String(),
+				License: stats.Licenses[file],
+				Mapping: mappings[file],
+				Score:   scores[file],
+				Reason:  stats.Reasons[file],
+			})
+		}
+	}
+	sort.Slice(r.Files, func(i, j int) bool {
+		return r.Files[i].Path < r.Files[j].Path
+	})
+
+	if options.Check {
+		failed := stats.Count(options.FailOn...) > 0
+		r.CheckFailed = &failed
+	}
+	return r
+}
+
+// printJSON writes to the standard output the report of the processing as JSON
+func printJSON(options *options.Options, stats *process.Stats) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(newReport(options, stats)); err != nil {
+		fmt.Fprintf(os.Stderr, "could not print the report: %s\n", err.Error())
+	}
+}
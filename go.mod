@@ -5,6 +5,7 @@ go 1.21
 require (
 	github.com/gookit/color v1.5.4
 	github.com/stretchr/testify v1.8.4
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -28,24 +28,66 @@ import (
 	"flag"
 	"fmt"
 	"github.com/lluissm/license-header-checker/pkg/process"
+	"os"
 	"regexp"
 	"strings"
 )
 
+// DefaultFailOn are the actions that count as failures in check mode when -fail-on is not supplied
+var DefaultFailOn = []process.Action{process.SkippedAdd, process.SkippedReplace, process.YearOutdated, process.Misplaced, process.ForeignLicense, process.DuplicateHeader, process.SkippedRemove}
+
 // Options are the process.Options parsed from command line flags/args
 type Options struct {
 	ShowVersion bool
 	Verbose     bool
+	JSON        bool
+	Check       bool
+	FailOn      []process.Action
 	Process     *process.Options
 }
 
+// variablesFlag is a flag that can be supplied multiple times with name=value pairs
+type variablesFlag map[string]string
+
+func (v variablesFlag) String() string {
+	return fmt.Sprint(map[string]string(v))
+}
+
+func (v variablesFlag) Set(value string) error {
+	name, val, ok := strings.Cut(value, "=")
+	if !ok || len(name) == 0 {
+		return fmt.Errorf("invalid variable %q, the expected format is name=value", value)
+	}
+	v[name] = val
+	return nil
+}
+
+// regexpsFlag is a flag that can be supplied multiple times with regular expressions
+type regexpsFlag []*regexp.Regexp
+
+func (r *regexpsFlag) String() string {
+	return fmt.Sprint([]*regexp.Regexp(*r))
+}
+
+func (r *regexpsFlag) Set(value string) error {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, re)
+	return nil
+}
+
 // Parse returns the parsed Options from command line flags/args
 func Parse(osArgs []string) (*Options, error) {
 
 	flagSet := flag.NewFlagSet("lhc", flag.ExitOnError)
 	flagSet.Usage = func() {
 		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "\033[1;4mSYNOPSIS\033[0m\n\n")
-		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "license-header-checker [-a] [-r] [-v] [-i path1,...] license-header-path src-path extensions...\n\n")
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "license-header-checker [-a] [-r] [-y] [-v] [-check] [-fail-on action1,...] [-i path1,...] [-include pattern1,...] [-gitignore] [-allow path1,...] [-allow-spdx id1,...] [-e regex] [-languages path] [-blank-lines n] [-preamble-blank-lines n] [-merge-copyrights above|below] [-map path] [-map-only] [-normalize level1,...] [-ok-similarity n] [-foreign-similarity n] [-json] [-keywords word1,...] [-header-rule regex...] [-not-header-rule regex...] [-generated regex...] [-include-generated] [-var name=value...] license-header-path src-path extensions...\n\n")
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "license-header-checker -spdx expression [-a] [-r] [-v] [-i path1,...] src-path extensions...\n\n")
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "license-header-checker -remove [-r] [-v] [-i path1,...] license-header-path src-path extensions...\n\n")
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "license-header-checker -remove-any [-r] [-v] [-i path1,...] src-path extensions...\n\n")
 		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "\033[1;4mOPTIONS\033[0m\n\n")
 		flagSet.PrintDefaults()
 		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "\n\033[1;4mEXAMPLE\033[0m\n\n")
@@ -54,9 +96,39 @@ func Parse(osArgs []string) (*Options, error) {
 
 	addFlag := flagSet.Bool("a", false, "Add the target license in case the file does not have any.")
 	replaceFlag := flagSet.Bool("r", false, "Replace the existing license by the target one in case they are different.")
-	ignorePathsFlag := flagSet.String("i", "", "A comma separated list of the folders, files and/or paths that should be ignored. Does not support wildcards.")
+	ignorePathsFlag := flagSet.String("i", "", "A comma separated list of the folders, files and/or paths that should be ignored. Supports glob patterns (*, ?, [...] and **), negation (!) and anchoring to the src-path (/).")
+	includeFlag := flagSet.String("include", "", "A comma separated list of the patterns (with .gitignore syntax) of the files to process besides the ones with the selected extensions, optionally followed by =language to select their comment style (e.g. Dockerfile*,scripts/*=shell).")
+	gitignoreFlag := flagSet.Bool("gitignore", false, "Ignore the paths excluded by the .gitignore files of the project and by .git/info/exclude.")
 	verboseFlag := flagSet.Bool("v", false, "Be verbose during execution printing options, files being processed, execution time, ...")
-	headerRegexFlag := flagSet.String("e", "", "Custom regular expression to support other comment types. If not supplied, the default one will be used (for /* ... */ style comments)")
+	headerRegexFlag := flagSet.String("e", "", "Custom regular expression to find the license header. If not supplied, the comment style of each file's language will be used.")
+	languagesFlag := flagSet.String("languages", "", "Path to a JSON file with languages to add to the built-in registry (or to replace the built-in ones with the same name).")
+	checkFlag := flagSet.Bool("check", false, "Exit with status 1 if any file ends up in one of the -fail-on actions and with status 2 if there were errors.")
+	failOnFlag := flagSet.String("fail-on", "", "A comma separated list of the actions that make the check fail (implies -check). Defaults to skipped_add,skipped_replace,year_outdated,misplaced,foreign_license,duplicate_header,skipped_remove.")
+	updateYearFlag := flagSet.Bool("y", false, "Check that the copyright years of the licenses are not older than the last modification of the files (they are updated with -r).")
+	yearFromGitFlag := flagSet.Bool("year-from-git", false, "Use the date of the last commit of the files instead of their modification time with -y.")
+	spdxFlag := flagSet.String("spdx", "", "SPDX license expression (e.g. \"Apache-2.0 OR MIT\") to check in the SPDX-License-Identifier line of the files instead of a license header. The license-header-path argument must be omitted.")
+	allowFlag := flagSet.String("allow", "", "A comma separated list of the paths of other license headers that are accepted besides the target one (they are never added nor replaced).")
+	allowSPDXFlag := flagSet.String("allow-spdx", "", "A comma separated list of SPDX license expressions that are accepted besides the target license (e.g. BSD-3-Clause,Apache-2.0).")
+	blankLinesFlag := flagSet.Int("blank-lines", process.DefaultSpacing.After, "Number of blank lines between an inserted license header and the code.")
+	preambleBlankLinesFlag := flagSet.Int("preamble-blank-lines", process.DefaultSpacing.Before, "Number of blank lines between the preamble of a file (e.g. shebang or build tags) and an inserted license header.")
+	keywordsFlag := flagSet.String("keywords", "", "A comma separated list of the words (case insensitive) that make a comment a license header. Defaults to copyright,license.")
+	var headerRules, notHeaderRules regexpsFlag
+	flagSet.Var(&headerRules, "header-rule", "A regular expression that makes the comments that match it license headers. It can be supplied multiple times.")
+	flagSet.Var(&notHeaderRules, "not-header-rule", "A regular expression that prevents the comments that match it from being license headers. It can be supplied multiple times.")
+	var generatedMarkers regexpsFlag
+	flagSet.Var(&generatedMarkers, "generated", "A regular expression that marks the files generated by a tool, which are skipped (replaces the built-in markers). It can be supplied multiple times.")
+	includeGeneratedFlag := flagSet.Bool("include-generated", false, "Process the files generated by a tool as any other file.")
+	okSimilarityFlag := flagSet.Float64("ok-similarity", 0, "Minimum similarity (from 0 to 1) of a license header with the target one to be considered ok. Disabled if 0.")
+	foreignSimilarityFlag := flagSet.Float64("foreign-similarity", 0, "License headers with a similarity (from 0 to 1) with the target one lower than this are reported as foreign and never replaced. Disabled if 0.")
+	jsonFlag := flagSet.Bool("json", false, "Print the result of the processing as JSON.")
+	normalizeFlag := flagSet.String("normalize", process.NormalizeLineEndings, "A comma separated list of the differences ignored when comparing the license headers with the target one (line_endings, whitespace, reflow, unicode or all).")
+	mapFlag := flagSet.String("map", "", "Path to a JSON file with the mappings of the old license headers to replace (with -r) and the new ones.")
+	mapOnlyFlag := flagSet.Bool("map-only", false, "Only replace (with -r) the license headers that match one of the mappings.")
+	removeFlag := flagSet.Bool("remove", false, "Remove the license header of the files when it matches the target one (only reported without -r).")
+	removeAnyFlag := flagSet.Bool("remove-any", false, "Remove any license header found in the files (only reported without -r). The license-header-path argument must be omitted.")
+	mergeCopyrightsFlag := flagSet.String("merge-copyrights", "", "Keep the copyright lines of the replaced licenses placing them above or below the text of the target license (above|below).")
+	variables := variablesFlag{}
+	flagSet.Var(variables, "var", "A name=value pair with the value of one of the variables of the license header (e.g. -var holder=Acme). It can be supplied multiple times.")
 	showVersionFlag := flagSet.Bool("version", false, "Display version number")
 
 	if err := flagSet.Parse(osArgs[1:]); err != nil {
@@ -67,32 +139,62 @@ func Parse(osArgs []string) (*Options, error) {
 
 	if *showVersionFlag {
 		return &Options{
-			true,
-			false,
-			nil,
+			ShowVersion: true,
 		}, nil
 	}
 
-	if len(args) < 3 {
+	if len(*spdxFlag) > 0 && (*removeFlag || *removeAnyFlag) {
+		return nil, errors.New("the -remove and -remove-any options cannot be used with -spdx")
+	}
+
+	// In SPDX mode and when removing any header, there is no license header file
+	var spdx, licensePath string
+	if len(*spdxFlag) > 0 {
+		expression, err := process.ParseSPDX(*spdxFlag)
+		if err != nil {
+			return nil, err
+		}
+		spdx = expression.String()
+	} else if len(args) > 0 && !*removeAnyFlag {
+		licensePath, args = args[0], args[1:]
+	}
+
+	// The extensions can be omitted when there are include patterns
+	if len(args) < 2 && (len(args) < 1 || len(*includeFlag) == 0) {
 		return nil, errors.New("missing arguments, please see documentation")
 	}
 
-	licensePath := args[0]
-	path := args[1]
+	path := args[0]
 
 	var extensions []string
-	for _, e := range args[2:] {
+	for _, e := range args[1:] {
 		extensions = append(extensions, "."+e)
 	}
 
-	var ignorePaths []string
-	for _, p := range strings.Split(*ignorePathsFlag, ",") {
+	ignorePaths, err := process.ParseIgnorePaths(*ignorePathsFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	var allowedLicensePaths []string
+	for _, p := range strings.Split(*allowFlag, ",") {
 		if len(p) > 0 {
-			ignorePaths = append(ignorePaths, p)
+			allowedLicensePaths = append(allowedLicensePaths, p)
+		}
+	}
+
+	var allowedSPDX []string
+	for _, e := range strings.Split(*allowSPDXFlag, ",") {
+		if e = strings.TrimSpace(e); len(e) > 0 {
+			expression, err := process.ParseSPDX(e)
+			if err != nil {
+				return nil, err
+			}
+			allowedSPDX = append(allowedSPDX, expression.String())
 		}
 	}
 
-	var headerRegex = process.DefaultRegex
+	var headerRegex *regexp.Regexp
 	if headerRegexFlag != nil && len(*headerRegexFlag) > 0 {
 		rex, err := regexp.Compile(*headerRegexFlag)
 		if err != nil {
@@ -101,19 +203,119 @@ func Parse(osArgs []string) (*Options, error) {
 		headerRegex = rex
 	}
 
+	languages := process.DefaultLanguages()
+	if len(*languagesFlag) > 0 {
+		data, err := os.ReadFile(*languagesFlag)
+		if err != nil {
+			return nil, err
+		}
+		customLanguages, err := process.ParseLanguages(data)
+		if err != nil {
+			return nil, err
+		}
+		languages = languages.Merge(customLanguages)
+	}
+
+	includes, err := process.ParseIncludes(*includeFlag, languages)
+	if err != nil {
+		return nil, err
+	}
+
+	if *blankLinesFlag < 0 || *preambleBlankLinesFlag < 0 {
+		return nil, errors.New("the number of blank lines cannot be negative")
+	}
+
+	if *okSimilarityFlag < 0 || *okSimilarityFlag > 1 || *foreignSimilarityFlag < 0 || *foreignSimilarityFlag > 1 {
+		return nil, errors.New("the similarity thresholds must be between 0 and 1")
+	}
+	if *okSimilarityFlag > 0 && *foreignSimilarityFlag > *okSimilarityFlag {
+		return nil, errors.New("the -foreign-similarity threshold cannot be higher than the -ok-similarity one")
+	}
+
+	var keywords []string
+	for _, k := range strings.Split(*keywordsFlag, ",") {
+		if k = strings.TrimSpace(k); len(k) > 0 {
+			keywords = append(keywords, k)
+		}
+	}
+
+	if *includeGeneratedFlag {
+		generatedMarkers = regexpsFlag{}
+	} else if len(generatedMarkers) == 0 {
+		generatedMarkers = nil
+	}
+
+	normalize, err := process.ParseNormalize(*normalizeFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	var mappings []*process.Mapping
+	if len(*mapFlag) > 0 {
+		data, err := os.ReadFile(*mapFlag)
+		if err != nil {
+			return nil, err
+		}
+		mappings, err = process.ParseMappings(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if m := *mergeCopyrightsFlag; len(m) > 0 && m != process.MergeAbove && m != process.MergeBelow {
+		return nil, fmt.Errorf("invalid -merge-copyrights value %q, it must be %s or %s", m, process.MergeAbove, process.MergeBelow)
+	}
+
+	failOn := DefaultFailOn
+	if len(*failOnFlag) > 0 {
+		failOn = nil
+		for _, name := range strings.Split(*failOnFlag, ",") {
+			action, err := process.ParseAction(strings.TrimSpace(name))
+			if err != nil {
+				return nil, err
+			}
+			failOn = append(failOn, action)
+		}
+	}
+
 	processOptions := &process.Options{
-		Add:         *addFlag,
-		Replace:     *replaceFlag,
-		Path:        path,
-		LicensePath: licensePath,
-		Extensions:  extensions,
-		IgnorePaths: ignorePaths,
-		HeaderRegex: headerRegex,
+		Add:                 *addFlag,
+		Replace:             *replaceFlag,
+		Path:                path,
+		LicensePath:         licensePath,
+		Extensions:          extensions,
+		IgnorePaths:         ignorePaths,
+		HeaderRegex:         headerRegex,
+		Languages:           languages,
+		Variables:           variables,
+		UpdateYear:          *updateYearFlag,
+		YearFromGit:         *yearFromGitFlag,
+		SPDX:                spdx,
+		AllowedLicensePaths: allowedLicensePaths,
+		AllowedSPDX:         allowedSPDX,
+		Spacing:             &process.Spacing{Before: *preambleBlankLinesFlag, After: *blankLinesFlag},
+		MergeCopyrights:     *mergeCopyrightsFlag,
+		Remove:              *removeFlag,
+		RemoveAny:           *removeAnyFlag,
+		Mappings:            mappings,
+		MappingsOnly:        *mapOnlyFlag,
+		Normalize:           normalize,
+		OkSimilarity:        *okSimilarityFlag,
+		ForeignSimilarity:   *foreignSimilarityFlag,
+		Keywords:            keywords,
+		HeaderRules:         headerRules,
+		NotHeaderRules:      notHeaderRules,
+		GeneratedMarkers:    generatedMarkers,
+		Gitignore:           *gitignoreFlag,
+		Includes:            includes,
 	}
 
 	return &Options{
-		*showVersionFlag,
-		*verboseFlag,
-		processOptions,
+		ShowVersion: *showVersionFlag,
+		Verbose:     *verboseFlag,
+		JSON:        *jsonFlag,
+		Check:       *checkFlag || len(*failOnFlag) > 0,
+		FailOn:      failOn,
+		Process:     processOptions,
 	}, nil
 }
@@ -24,9 +24,12 @@ SOFTWARE.
 package options
 
 import (
+	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 
+	"github.com/lluissm/license-header-checker/pkg/process"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -131,4 +134,301 @@ func TestHeaderRegex(t *testing.T) {
 	args := []string{"license-header-checker", "-e", headerRegexStr, "license-path", "source-path", "js", "ts"}
 	options, _ := Parse(args)
 	assert.Equal(t, headerRegex, options.Process.HeaderRegex)
+
+	args = []string{"license-header-checker", "license-path", "source-path", "js", "ts"}
+	options, _ = Parse(args)
+	assert.Nil(t, options.Process.HeaderRegex)
+}
+
+func TestLanguages(t *testing.T) {
+	args := []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, _ := Parse(args)
+	assert.Equal(t, process.DefaultLanguages(), options.Process.Languages)
+
+	path := filepath.Join(t.TempDir(), "languages.json")
+	err := os.WriteFile(path, []byte(`[{"name": "nim", "extensions": [".nim"], "line_prefix": "#"}]`), 0o600)
+	assert.Nil(t, err)
+	args = []string{"license-header-checker", "-languages", path, "license-path", "source-path", "nim"}
+	options, err = Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "nim", options.Process.Languages.Find("main.nim").Name)
+
+	args = []string{"license-header-checker", "-languages", "missing.json", "license-path", "source-path", "nim"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	args := []string{"license-header-checker", "-check", "license-path", "source-path", "js"}
+	options, _ := Parse(args)
+	assert.True(t, options.Check)
+	assert.Equal(t, DefaultFailOn, options.FailOn)
+
+	args = []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, _ = Parse(args)
+	assert.False(t, options.Check)
+}
+
+func TestFailOn(t *testing.T) {
+	args := []string{"license-header-checker", "-fail-on", "license_added,license_replaced", "license-path", "source-path", "js"}
+	options, _ := Parse(args)
+	assert.True(t, options.Check)
+	assert.Equal(t, []process.Action{process.LicenseAdded, process.LicenseReplaced}, options.FailOn)
+
+	args = []string{"license-header-checker", "-fail-on", "unknown", "license-path", "source-path", "js"}
+	_, err := Parse(args)
+	assert.NotNil(t, err)
+}
+
+func TestVariables(t *testing.T) {
+	args := []string{"license-header-checker", "-var", "holder=Acme Inc.", "-var", "year=2024", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"holder": "Acme Inc.", "year": "2024"}, options.Process.Variables)
+
+	args = []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, _ = Parse(args)
+	assert.Empty(t, options.Process.Variables)
+}
+
+func TestUpdateYear(t *testing.T) {
+	args := []string{"license-header-checker", "-y", "-year-from-git", "license-path", "source-path", "js"}
+	options, _ := Parse(args)
+	assert.True(t, options.Process.UpdateYear)
+	assert.True(t, options.Process.YearFromGit)
+
+	args = []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, _ = Parse(args)
+	assert.False(t, options.Process.UpdateYear)
+	assert.False(t, options.Process.YearFromGit)
+}
+
+func TestSPDX(t *testing.T) {
+	args := []string{"license-header-checker", "-spdx", "apache-2.0 or MIT", "source-path", "js", "ts"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "apache-2.0 OR MIT", options.Process.SPDX)
+	assert.Equal(t, "", options.Process.LicensePath)
+	assert.Equal(t, "source-path", options.Process.Path)
+	assert.Equal(t, []string{".js", ".ts"}, options.Process.Extensions)
+
+	args = []string{"license-header-checker", "-spdx", "MIT OR", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+
+	args = []string{"license-header-checker", "-spdx", "MIT", "source-path"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+}
+
+func TestAllowedLicenses(t *testing.T) {
+	args := []string{"license-header-checker", "-allow", "bsd.txt,apache.txt", "-allow-spdx", "BSD-3-Clause,apache-2.0 or MIT", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bsd.txt", "apache.txt"}, options.Process.AllowedLicensePaths)
+	assert.Equal(t, []string{"BSD-3-Clause", "apache-2.0 OR MIT"}, options.Process.AllowedSPDX)
+
+	args = []string{"license-header-checker", "-allow-spdx", "MIT OR", "license-path", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+
+	args = []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, _ = Parse(args)
+	assert.Empty(t, options.Process.AllowedLicensePaths)
+	assert.Empty(t, options.Process.AllowedSPDX)
+}
+
+func TestSpacing(t *testing.T) {
+	args := []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, process.DefaultSpacing, *options.Process.Spacing)
+
+	args = []string{"license-header-checker", "-blank-lines", "2", "-preamble-blank-lines", "0", "license-path", "source-path", "js"}
+	options, err = Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, process.Spacing{Before: 0, After: 2}, *options.Process.Spacing)
+
+	args = []string{"license-header-checker", "-blank-lines", "-1", "license-path", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+}
+
+func TestMergeCopyrights(t *testing.T) {
+	args := []string{"license-header-checker", "-merge-copyrights", "below", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, process.MergeBelow, options.Process.MergeCopyrights)
+
+	args = []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, _ = Parse(args)
+	assert.Empty(t, options.Process.MergeCopyrights)
+
+	args = []string{"license-header-checker", "-merge-copyrights", "middle", "license-path", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+}
+
+func TestRemove(t *testing.T) {
+	args := []string{"license-header-checker", "-remove", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.True(t, options.Process.Remove)
+	assert.Equal(t, "license-path", options.Process.LicensePath)
+
+	// The license path is omitted when removing any license
+	args = []string{"license-header-checker", "-remove-any", "source-path", "js"}
+	options, err = Parse(args)
+	assert.Nil(t, err)
+	assert.True(t, options.Process.RemoveAny)
+	assert.Empty(t, options.Process.LicensePath)
+	assert.Equal(t, "source-path", options.Process.Path)
+
+	args = []string{"license-header-checker", "-remove", "-spdx", "MIT", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+}
+
+func TestMappings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mappings.json")
+	err := os.WriteFile(path, []byte(`[{"name": "acme", "old": "Copyright Acme Corp.", "new": "Copyright Acme Inc."}]`), 0o600)
+	assert.Nil(t, err)
+	args := []string{"license-header-checker", "-map", path, "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Len(t, options.Process.Mappings, 1)
+	assert.Equal(t, "acme", options.Process.Mappings[0].Name)
+	assert.False(t, options.Process.MappingsOnly)
+
+	args = []string{"license-header-checker", "-map", path, "-map-only", "license-path", "source-path", "js"}
+	options, err = Parse(args)
+	assert.Nil(t, err)
+	assert.True(t, options.Process.MappingsOnly)
+
+	args = []string{"license-header-checker", "-map", "missing.json", "license-path", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+}
+
+func TestNormalize(t *testing.T) {
+	args := []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{process.NormalizeLineEndings}, options.Process.Normalize)
+
+	args = []string{"license-header-checker", "-normalize", "whitespace,unicode", "license-path", "source-path", "js"}
+	options, err = Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{process.NormalizeWhitespace, process.NormalizeUnicode}, options.Process.Normalize)
+
+	args = []string{"license-header-checker", "-normalize", "", "license-path", "source-path", "js"}
+	options, err = Parse(args)
+	assert.Nil(t, err)
+	assert.Empty(t, options.Process.Normalize)
+
+	args = []string{"license-header-checker", "-normalize", "spaces", "license-path", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+}
+
+func TestSimilarity(t *testing.T) {
+	args := []string{"license-header-checker", "-ok-similarity", "0.95", "-foreign-similarity", "0.5", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.95, options.Process.OkSimilarity)
+	assert.Equal(t, 0.5, options.Process.ForeignSimilarity)
+
+	args = []string{"license-header-checker", "-ok-similarity", "1.5", "license-path", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+
+	args = []string{"license-header-checker", "-ok-similarity", "0.5", "-foreign-similarity", "0.9", "license-path", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+}
+
+func TestJSON(t *testing.T) {
+	args := []string{"license-header-checker", "-json", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.True(t, options.JSON)
+
+	args = []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, _ = Parse(args)
+	assert.False(t, options.JSON)
+}
+
+func TestDetectionRules(t *testing.T) {
+	args := []string{"license-header-checker", "-keywords", "lizenz, urheberrecht", "-header-rule", "(?i)all rights reserved",
+		"-not-header-rule", "^// Package ", "-not-header-rule", "generated", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"lizenz", "urheberrecht"}, options.Process.Keywords)
+	assert.Len(t, options.Process.HeaderRules, 1)
+	assert.Equal(t, "(?i)all rights reserved", options.Process.HeaderRules[0].String())
+	assert.Len(t, options.Process.NotHeaderRules, 2)
+	assert.Equal(t, "generated", options.Process.NotHeaderRules[1].String())
+
+	args = []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, _ = Parse(args)
+	assert.Nil(t, options.Process.Keywords)
+	assert.Empty(t, options.Process.HeaderRules)
+}
+
+func TestGenerated(t *testing.T) {
+	args := []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Nil(t, options.Process.GeneratedMarkers)
+
+	args = []string{"license-header-checker", "-generated", "@generated", "-generated", "(?i)generated by protoc", "license-path", "source-path", "js"}
+	options, err = Parse(args)
+	assert.Nil(t, err)
+	assert.Len(t, options.Process.GeneratedMarkers, 2)
+	assert.Equal(t, "(?i)generated by protoc", options.Process.GeneratedMarkers[1].String())
+
+	args = []string{"license-header-checker", "-include-generated", "license-path", "source-path", "js"}
+	options, err = Parse(args)
+	assert.Nil(t, err)
+	assert.NotNil(t, options.Process.GeneratedMarkers)
+	assert.Empty(t, options.Process.GeneratedMarkers)
+}
+
+func TestIgnorePatterns(t *testing.T) {
+	args := []string{"license-header-checker", "-i", "node_modules,**/*_mock.go,!/src/keep.go", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"node_modules", "**/*_mock.go", "!/src/keep.go"}, options.Process.IgnorePaths)
+
+	args = []string{"license-header-checker", "-i", "src/[a-", "license-path", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+}
+
+func TestGitignore(t *testing.T) {
+	args := []string{"license-header-checker", "-gitignore", "license-path", "source-path", "js"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.True(t, options.Process.Gitignore)
+
+	args = []string{"license-header-checker", "license-path", "source-path", "js"}
+	options, _ = Parse(args)
+	assert.False(t, options.Process.Gitignore)
+}
+
+func TestIncludes(t *testing.T) {
+	args := []string{"license-header-checker", "-include", "Dockerfile*,bin/*=shell", "license-path", "source-path"}
+	options, err := Parse(args)
+	assert.Nil(t, err)
+	assert.Len(t, options.Process.Includes, 2)
+	assert.Equal(t, "shell", options.Process.Includes[1].Language)
+	assert.Empty(t, options.Process.Extensions)
+
+	args = []string{"license-header-checker", "license-path", "source-path"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
+
+	args = []string{"license-header-checker", "-include", "bin/*=unknown", "license-path", "source-path", "js"}
+	_, err = Parse(args)
+	assert.NotNil(t, err)
 }
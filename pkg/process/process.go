@@ -24,9 +24,10 @@ SOFTWARE.
 package process
 
 import (
+	"fmt"
 	"io/fs"
+	"path/filepath"
 	"regexp"
-	"strings"
 	"time"
 )
 
@@ -34,21 +35,61 @@ type (
 	// Action performed when processing a file
 	Action int
 
-	// Operation is the result of processing one file
+	// Operation is the result of processing one file. License is the name of the allowed
+	// license found in the file when it is not the target one. Header is the region of the
+	// original content matched as the license header or SPDX expression (nil if there was none).
+	// Mapping is the name of the mapping that matched the header of the file (if any). Score is the
+	// similarity of the header with the target license when it did not match (nil if not computed).
+	// Reason is the one given by the directive of an exempted file (if any).
 	Operation struct {
-		Action Action
-		Path   string
+		Action  Action
+		Path    string
+		License string
+		Header  *HeaderMatch
+		Mapping string
+		Score   *float64
+		Reason  string
 	}
 
 	// Options to be followed during processing
 	Options struct {
-		Add         bool
-		Replace     bool
-		Path        string
-		LicensePath string
-		Extensions  []string
-		IgnorePaths []string
-		HeaderRegex *regexp.Regexp
+		Add                 bool
+		Replace             bool
+		Path                string
+		LicensePath         string
+		Extensions          []string
+		IgnorePaths         []string
+		HeaderRegex         *regexp.Regexp
+		Languages           Languages
+		Variables           map[string]string
+		UpdateYear          bool
+		YearFromGit         bool
+		SPDX                string
+		AllowedLicensePaths []string
+		AllowedSPDX         []string
+		Spacing             *Spacing
+		MergeCopyrights     string
+		Remove              bool
+		RemoveAny           bool
+		Mappings            []*Mapping
+		MappingsOnly        bool
+		Normalize           []string
+		OkSimilarity        float64
+		ForeignSimilarity   float64
+		Keywords            []string
+		HeaderRules         []*regexp.Regexp
+		NotHeaderRules      []*regexp.Regexp
+		GeneratedMarkers    []*regexp.Regexp
+		Gitignore           bool
+		Includes            []*Include
+	}
+
+	// Spacing defines the blank lines around an inserted license header
+	Spacing struct {
+		// Before is the number of blank lines between the preamble (if any) and the header
+		Before int
+		// After is the number of blank lines between the header and the rest of the content
+		After int
 	}
 )
 
@@ -67,14 +108,102 @@ const (
 	LicenseReplaced
 	// OperationError means there was an error with one of the files
 	OperationError
+	// YearOutdated means that the copyright year of the license is older than the last
+	// modification of the file but it was not updated as the -r flag was not provided
+	YearOutdated
+	// YearUpdated means that the copyright year of the license was updated to the year of
+	// the last modification of the file
+	YearUpdated
+	// Misplaced means that the file had the target license but not at the top of the file
+	// and it was not moved as the -r flag was not provided
+	Misplaced
+	// LicenseMoved means that the target license was moved to the top of the file
+	LicenseMoved
+	// LicenseMerged means that the file's license was replaced by the target one keeping
+	// the copyright lines of the previous license
+	LicenseMerged
+	// LicenseRemoved means that the license header was removed from the file
+	LicenseRemoved
+	// ForeignLicense means that the file had a license too different from the target one
+	// to be replaced
+	ForeignLicense
+	// DuplicateHeader means that the leading comments of the file had the target license more than
+	// once (or along with other license headers) but they were not fixed as the -r flag was not provided
+	DuplicateHeader
+	// DuplicateFixed means that the leading comments of the file were collapsed to a single target license
+	DuplicateFixed
+	// SkippedGenerated means that the file was not processed as it is generated by a tool
+	SkippedGenerated
+	// Exempted means that the file was not processed as it contains the license-header-checker:ignore directive
+	Exempted
+	// SkippedRemove means that the file had a license header to remove but it was not removed
+	// as the -r flag was not provided
+	SkippedRemove
+	// NoHeader means that the file had no license header to remove
+	NoHeader
 )
 
+// actionNames are the names used to refer to each action in the reports and the cli options
+var actionNames = map[Action]string{
+	SkippedAdd:       "skipped_add",
+	SkippedReplace:   "skipped_replace",
+	LicenseOk:        "license_ok",
+	LicenseAdded:     "license_added",
+	LicenseReplaced:  "license_replaced",
+	OperationError:   "error",
+	YearOutdated:     "year_outdated",
+	YearUpdated:      "year_updated",
+	Misplaced:        "misplaced",
+	LicenseMoved:     "license_moved",
+	LicenseMerged:    "license_merged",
+	LicenseRemoved:   "license_removed",
+	ForeignLicense:   "foreign_license",
+	DuplicateHeader:  "duplicate_header",
+	DuplicateFixed:   "duplicate_fixed",
+	SkippedGenerated: "skipped_generated",
+	Exempted:         "exempted",
+	SkippedRemove:    "skipped_remove",
+	NoHeader:         "no_header",
+}
+
+// String returns the name of the action
+func (a Action) String() string {
+	if name, ok := actionNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("action(%d)", int(a))
+}
+
+// ParseAction returns the action with the provided name
+func ParseAction(name string) (Action, error) {
+	for action, actionName := range actionNames {
+		if actionName == name {
+			return action, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown action: %s", name)
+}
+
+// DefaultSpacing is used when no spacing is provided in the options
+var DefaultSpacing = Spacing{Before: 1, After: 1}
+
+// spacing returns the blank lines to be left around the inserted headers
+func (o *Options) spacing() Spacing {
+	if o.Spacing == nil {
+		return DefaultSpacing
+	}
+	return *o.Spacing
+}
+
 // fileHandler defines the interface to manage files during processing
 type fileHandler interface {
 	// ReadFile reads the named file and returns the contents. A successful call returns
 	// err == nil, not err == EOF. Because ReadFile reads the whole file, it does not treat
 	// an EOF from Read as an error to be reported.
 	ReadFile(name string) ([]byte, error)
+	// ReadPrefix reads up to n bytes from the beginning of the named file. Reaching the end
+	// of the file before reading n bytes is not an error.
+	ReadPrefix(name string, n int) ([]byte, error)
 	// WalkDir walks the file tree rooted at root, calling fn for each file or
 	// directory in the tree, including root.
 	WalkDir(path string, fn fs.WalkDirFunc) error
@@ -82,53 +211,213 @@ type fileHandler interface {
 	// WriteFile creates it with permissions perm (before umask); otherwise WriteFile
 	// truncates it before writing, without changing permissions.
 	WriteFile(name string, content []byte) error
+	// ModTime returns the modification time of the named file.
+	ModTime(name string) (time.Time, error)
+	// CommitTime returns the time of the last commit that modified the named file.
+	CommitTime(name string) (time.Time, error)
 }
 
 // File processes one file
 func File(path string, content string, license string, options *Options, h fileHandler) Action {
+	return FileOperation(path, content, license, options, h).Action
+}
+
+// FileOperation processes one file returning the details of the operation, including the
+// region of the content matched as the license header (which is the one rewritten when replacing)
+func FileOperation(path string, content string, license string, options *Options, h fileHandler) *Operation {
+	return fileOperation(path, content, &licenseSet{target: license}, options, h)
+}
+
+// fileOperation processes one file returning the details of the operation
+func fileOperation(path string, content string, licenses *licenseSet, options *Options, h fileHandler) *Operation {
+
+	// The lines written to the files use their dominant line endings
+	if usesCRLF(content) {
+		h = crlfHandler{fileHandler: h, original: content}
+	}
+
+	lang := options.fileLanguage(path, content)
+
+	if ok, reason := findDirective(lang, content); ok {
+		return &Operation{Action: Exempted, Path: path, Reason: reason}
+	}
+
+	// Generated files are not licensed by us and regenerating them would wipe out their header anyway
+	if options.isGenerated(lang, content) {
+		return &Operation{Action: SkippedGenerated, Path: path}
+	}
+
+	if options.Remove || options.RemoveAny {
+		return removeFile(path, content, licenses, options, h)
+	}
+
+	if len(options.SPDX) > 0 {
+		return spdxFile(path, content, licenses, options, h)
+	}
+
+	tmpl, err := newLicenseTemplate(path, options.renderLicense(licenses.target, lang), options)
+	if err != nil {
+		return &Operation{Action: OperationError, Path: path}
+	}
+
+	loc := tmpl.pattern.FindStringIndex(content)
+	// Licenses that are not a comment on their own (e.g. inside a string literal) are part of the code,
+	// so the file is handled as if it did not contain the target license
+	if loc != nil && !isLeading(lang, content, loc[0]) && !isStandaloneComment(lang, content, loc[0], loc[1]) {
+		loc = nil
+	}
+	if loc != nil {
+		match := &HeaderMatch{Start: loc[0], End: loc[1], Text: content[loc[0]:loc[1]]}
+		if !isLeading(lang, content, loc[0]) {
+			if options.Replace {
+				newContent := moveHeader(lang, content, loc[0], loc[1], options)
+				if err := h.WriteFile(path, []byte(newContent)); err != nil {
+					return &Operation{Action: OperationError, Path: path}
+				}
+				return &Operation{Action: LicenseMoved, Path: path, Header: match}
+			}
+			return &Operation{Action: Misplaced, Path: path, Header: match}
+		}
+		if duplicates := options.duplicateHeaders(path, lang, content, tmpl, match); len(duplicates) > 0 {
+			if options.Replace {
+				newContent := collapseHeaders(lang, content, match, duplicates, options.spacing())
+				if err := h.WriteFile(path, []byte(newContent)); err != nil {
+					return &Operation{Action: OperationError, Path: path}
+				}
+				return &Operation{Action: DuplicateFixed, Path: path, Header: match}
+			}
+			return &Operation{Action: DuplicateHeader, Path: path, Header: match}
+		}
+		if options.UpdateYear {
+			return &Operation{Action: checkYear(path, content, loc[0], loc[1], options, h), Path: path, Header: match}
+		}
+		return &Operation{Action: LicenseOk, Path: path, Header: match}
+	}
+
+	if header := findHeader(lang, content); header != nil && options.matchesNormalized(path, header.Text, licenses.target, lang) {
+		if options.UpdateYear {
+			return &Operation{Action: checkYear(path, content, header.Start, header.End, options, h), Path: path, Header: header}
+		}
+		return &Operation{Action: LicenseOk, Path: path, Header: header}
+	}
+
+	if name, ok := licenses.matchAllowed(path, content, lang, options); ok {
+		return &Operation{Action: LicenseOk, Path: path, License: name}
+	}
+
+	// Headers already migrated to the new header of a mapping are not replaced again
+	if header := options.findMapped(path, content, lang); header != nil {
+		return &Operation{Action: LicenseOk, Path: path, Header: header}
+	}
+
+	if mapping, header := options.findMapping(path, content, lang); mapping != nil {
+		return mapFile(path, content, mapping, header, licenses, lang, options, h)
+	}
 
-	if strings.Contains(content, strings.TrimSpace(license)) {
-		return LicenseOk
+	license, err := tmpl.execute()
+	if err != nil {
+		return &Operation{Action: OperationError, Path: path}
+	}
+
+	// Headers similar enough to the target license are ok while the too different ones are foreign
+	var score float64
+	var computedScore *float64
+	if header := findHeader(lang, content); header != nil {
+		score = similarity(plainText(header.Text, options.languages()), plainText(license, options.languages()))
+		computedScore = &score
+		if options.OkSimilarity > 0 && score >= options.OkSimilarity {
+			return &Operation{Action: LicenseOk, Path: path, Header: header, Score: computedScore}
+		}
 	}
 
-	if containsLicenseHeader(options.HeaderRegex, content) {
+	if match := options.licenseHeader(lang, content); match != nil {
+		if score < options.ForeignSimilarity {
+			return &Operation{Action: ForeignLicense, Path: path, Header: match, Score: computedScore}
+		}
+		action := LicenseReplaced
+		if len(options.MergeCopyrights) > 0 {
+			if copyrights := copyrightLines(match.Text, license, options.languages()); len(copyrights) > 0 {
+				license = lang.mergeCopyrights(license, copyrights, options.MergeCopyrights)
+				if isMerged(match.Text, license) {
+					return &Operation{Action: LicenseOk, Path: path, Header: match}
+				}
+				action = LicenseMerged
+			}
+		}
+		// With options.MappingsOnly, only the headers that match a mapping are replaced
+		if options.Replace && options.MappingsOnly {
+			return &Operation{Action: SkippedReplace, Path: path, Header: match, Score: computedScore, Reason: NotMappedReason}
+		}
 		if options.Replace {
-			newContent := replaceHeader(options.HeaderRegex, content, license)
+			newContent := replaceHeader(lang, content, match, license, options.spacing())
 			if err := h.WriteFile(path, []byte(newContent)); err != nil {
-				return OperationError
+				return &Operation{Action: OperationError, Path: path}
 			}
-			return LicenseReplaced
+			return &Operation{Action: action, Path: path, Header: match, Score: computedScore}
 		}
-		return SkippedReplace
+		return &Operation{Action: SkippedReplace, Path: path, Header: match, Score: computedScore}
 	}
 
 	if options.Add {
-		newContent := insertHeader(content, license)
+		newContent := insertHeader(lang, content, license, options.spacing())
 		if err := h.WriteFile(path, []byte(newContent)); err != nil {
-			return OperationError
+			return &Operation{Action: OperationError, Path: path}
 		}
-		return LicenseAdded
+		return &Operation{Action: LicenseAdded, Path: path}
 	}
-	return SkippedAdd
+	return &Operation{Action: SkippedAdd, Path: path}
 }
 
 // Files processes a group of files (in parallel) following the configuration
 // defined in options
 func Files(options *Options, h fileHandler) (*Stats, error) {
 
-	data, err := h.ReadFile(options.LicensePath)
+	licenses, err := readLicenses(options, h)
 	if err != nil {
 		return nil, err
 	}
 
-	license := string(data)
 	channel := make(chan *Operation, 15)
 	startTime := time.Now()
 	stats := NewStats()
 	files := 0
 
+	ignores := &ignoreList{}
+	if options.Gitignore {
+		ignores.loadRepository(h, options.Path)
+	}
+
 	err = h.WalkDir(options.Path, func(path string, d fs.DirEntry, err error) error {
-		if processFile(channel, options, license, h, path, d, err) {
+		// The root of the project could not be read (e.g. it does not exist)
+		if d == nil {
+			return err
+		}
+		rel := relativePath(options.Path, path)
+		if d.IsDir() {
+			// The ignored directories are not walked at all
+			if rel != "." && options.skipsDir(path) {
+				return fs.SkipDir
+			}
+			if pattern := ignores.match(rel, true); rel != "." && pattern != nil {
+				stats.Ignored[path] = pattern.source
+				return fs.SkipDir
+			}
+			// The files of git are never processed
+			if filepath.Base(path) == ".git" {
+				return fs.SkipDir
+			}
+			if options.Gitignore {
+				ignores.load(h, filepath.Join(path, ".gitignore"), rel)
+			}
+			ignores.load(h, filepath.Join(path, LicenseignoreFile), rel)
+		} else if pattern := ignores.match(rel, false); pattern != nil {
+			// Only the files that would have been processed otherwise are reported
+			if !options.shouldIgnoreFile(path) {
+				stats.Ignored[path] = pattern.source
+			}
+			return nil
+		}
+		if processFile(channel, options, licenses, h, path, d, err) {
 			files++
 		}
 		return nil
@@ -146,21 +435,19 @@ func Files(options *Options, h fileHandler) (*Stats, error) {
 // processFile returns true if a file has been processed and false if processing has been skipped.
 //
 // Processing will be skipped if the path is a directory, it is part of the paths to ignore or the
-// file extension does not match any of the extensions in options.Extensions
+// file extension does not match any of the extensions in options.Extensions nor any of the patterns
+// in options.Includes. Extensionless files are not skipped if the interpreter of their shebang belongs
+// to a language with any of the extensions in options.Extensions.
 //
 // The processing of the file is done on a goroutine, hence the channel to write the result of the
 // operation
-func processFile(channel chan *Operation, options *Options, license string, h fileHandler, path string, d fs.DirEntry, err error) bool {
+func processFile(channel chan *Operation, options *Options, licenses *licenseSet, h fileHandler, path string, d fs.DirEntry, err error) bool {
 
 	if d.IsDir() {
 		return false
 	}
 
-	if shouldIgnorePath(path, options.IgnorePaths) {
-		return false
-	}
-
-	if shouldIgnoreExtension(path, options.Extensions) {
+	if options.ignoresPath(path) {
 		return false
 	}
 
@@ -169,6 +456,22 @@ func processFile(channel chan *Operation, options *Options, license string, h fi
 		return true
 	}
 
+	// The extensionless files are selected by the interpreter of their shebang, so their first line
+	// has to be read first (the rest of the file is only read if it is selected)
+	if options.shouldIgnoreFile(path) {
+		if len(filepath.Ext(path)) > 0 || !d.Type().IsRegular() {
+			return false
+		}
+		prefix, err := h.ReadPrefix(path, shebangLength)
+		if err != nil {
+			onError(channel, path)
+			return true
+		}
+		if !options.selectsInterpreter(string(prefix)) {
+			return false
+		}
+	}
+
 	data, err := h.ReadFile(path)
 	if err != nil {
 		onError(channel, path)
@@ -176,12 +479,7 @@ func processFile(channel chan *Operation, options *Options, license string, h fi
 	}
 
 	go func() {
-		content := string(data)
-		action := File(path, content, license, options, h)
-		channel <- &Operation{
-			Action: action,
-			Path:   path,
-		}
+		channel <- fileOperation(path, string(data), licenses, options, h)
 	}()
 
 	return true
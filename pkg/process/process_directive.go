@@ -0,0 +1,40 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import "regexp"
+
+// directiveRegex matches the comment that exempts a file from being processed, which can
+// optionally provide the reason (e.g. license-header-checker:ignore-file reason="third-party snippet")
+var directiveRegex = regexp.MustCompile(`license-header-checker:ignore(?:-file)?(?:[ \t]+reason="([^"]*)")?`)
+
+// findDirective returns true if the leading comments of the content contain the directive that
+// exempts the file from being processed, along with its reason (if any)
+func findDirective(lang *Language, content string) (bool, string) {
+	match := directiveRegex.FindStringSubmatch(content[:leadingCommentsEnd(lang, content)])
+	if match == nil {
+		return false, ""
+	}
+	return true, match[1]
+}
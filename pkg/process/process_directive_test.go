@@ -0,0 +1,69 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDirective(t *testing.T) {
+	golang := DefaultLanguages().Find("main.go")
+
+	ok, reason := findDirective(golang, "// license-header-checker:ignore\npackage main\n")
+	assert.True(t, ok)
+	assert.Empty(t, reason)
+
+	ok, reason = findDirective(golang, "/*\n * license-header-checker:ignore-file reason=\"third-party snippet\"\n */\npackage main\n")
+	assert.True(t, ok)
+	assert.Equal(t, "third-party snippet", reason)
+
+	// The directive is only honored in the leading comments
+	ok, _ = findDirective(golang, "package main\n\n// license-header-checker:ignore\n")
+	assert.False(t, ok)
+
+	python := DefaultLanguages().Find("main.py")
+	ok, reason = findDirective(python, "#!/usr/bin/env python\n# Copyright 2019 Someone\n# license-header-checker:ignore reason=\"fixture\"\nprint()\n")
+	assert.True(t, ok)
+	assert.Equal(t, "fixture", reason)
+}
+
+func TestFile_Exempted(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	options := &Options{Add: true, Replace: true}
+
+	op := FileOperation(fileName, "// license-header-checker:ignore-file reason=\"fixture\"\n\npackage main\n", testTargetLicenseHeader, options, handler)
+	assert.Equal(t, &Operation{Action: Exempted, Path: fileName, Reason: "fixture"}, op)
+
+	// Directives after the code are not honored
+	op = FileOperation(fileName, testFileWithDifferentLicense+"\n// license-header-checker:ignore\n", testTargetLicenseHeader, &Options{}, handler)
+	assert.Equal(t, SkippedReplace, op.Action)
+
+	stats := NewStats()
+	stats.AddOperation(&Operation{Action: Exempted, Path: "path1"})
+	stats.AddOperation(&Operation{Action: Exempted, Path: "path2", Reason: "fixture"})
+	assert.Equal(t, map[string]string{"path2": "fixture"}, stats.Reasons)
+}
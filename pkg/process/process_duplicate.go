@@ -0,0 +1,98 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"sort"
+)
+
+// duplicateSimilarity is the minimum similarity with the target license of the other license headers
+// that are duplicates when options.ForeignSimilarity is not set
+const duplicateSimilarity = 0.5
+
+// duplicateHeaders returns the license headers found in the leading comments of the content besides
+// the provided match of the target license, which are either more copies of the target license or
+// other (usually older) license headers
+func (o *Options) duplicateHeaders(path string, lang *Language, content string, tmpl *licenseTemplate, match *HeaderMatch) []*HeaderMatch {
+	start, end := lang.preambleEnd(content), leadingCommentsEnd(lang, content)
+	if match.Start < start || match.End > end {
+		return nil
+	}
+	license, err := tmpl.execute()
+	if err != nil {
+		return nil
+	}
+	var copies, duplicates []*HeaderMatch
+	for _, loc := range tmpl.pattern.FindAllStringIndex(content[start:end], -1) {
+		header := &HeaderMatch{Start: start + loc[0], End: start + loc[1], Text: content[start+loc[0] : start+loc[1]]}
+		copies = append(copies, header)
+		if header.Start != match.Start {
+			duplicates = append(duplicates, header)
+		}
+	}
+	for _, loc := range lang.headerRegex.FindAllStringIndex(content[start:end], -1) {
+		comment := &HeaderMatch{Start: start + loc[0], End: start + loc[1], Text: content[start+loc[0] : start+loc[1]]}
+		if !overlaps(comment, copies) && o.isLicenseHeader(comment.Text) && o.isOtherLicense(path, lang, comment, license) {
+			duplicates = append(duplicates, comment)
+		}
+	}
+	return duplicates
+}
+
+// isOtherLicense returns true if the header is the old header of a mapping or a header similar enough
+// to the target license. Other comments are never duplicates even if they mention a license (e.g. the
+// documentation of a package), and neither are the allowed licenses, which are never replaced.
+func (o *Options) isOtherLicense(path string, lang *Language, header *HeaderMatch, license string) bool {
+	for _, mapping := range o.Mappings {
+		if mapping.matches(path, header, lang, o) {
+			return true
+		}
+	}
+	threshold := o.ForeignSimilarity
+	if threshold <= 0 {
+		threshold = duplicateSimilarity
+	}
+	return similarity(plainText(header.Text, o.languages()), plainText(license, o.languages())) >= threshold
+}
+
+// overlaps returns true if the header shares any byte with one of the others
+func overlaps(header *HeaderMatch, others []*HeaderMatch) bool {
+	for _, other := range others {
+		if header.Start < other.End && other.Start < header.End {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseHeaders removes the provided match and its duplicates from the content and places the
+// match alone at the top of it, so that the leading comments contain a single license header
+func collapseHeaders(lang *Language, content string, match *HeaderMatch, duplicates []*HeaderMatch, spacing Spacing) string {
+	headers := append([]*HeaderMatch{match}, duplicates...)
+	sort.Slice(headers, func(i, j int) bool { return headers[i].Start > headers[j].Start })
+	for _, header := range headers {
+		content = removeRegion(content, header.Start, header.End)
+	}
+	return insertHeader(lang, content, match.Text, spacing)
+}
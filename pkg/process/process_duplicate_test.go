@@ -0,0 +1,98 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile_DuplicateHeader(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	license := "/* Copyright 2024 The Author, licensed under the MIT License */"
+	oldLicense := "/* Copyright 2019 The Author, licensed under the Apache License */"
+	fixed := license + "\n\npackage main\n"
+
+	// The target license twice
+	content := license + "\n\n" + license + "\n\npackage main\n"
+	op := FileOperation(fileName, content, license, &Options{}, handler)
+	assert.Equal(t, DuplicateHeader, op.Action)
+	assert.Equal(t, &HeaderMatch{Start: 0, End: len(license), Text: license}, op.Header)
+
+	// An old header followed by the target license
+	content = oldLicense + "\n\n" + license + "\n\npackage main\n"
+	assert.Equal(t, DuplicateHeader, File(fileName, content, license, &Options{}, handler))
+
+	// Comments that are not licenses are not duplicates
+	content = license + "\n\n// Package main does things\npackage main\n"
+	assert.Equal(t, LicenseOk, File(fileName, content, license, &Options{}, handler))
+
+	// Comments that only mention a license are not duplicates either
+	content = license + "\n\n// Package license parses the license files of a project.\npackage license\n"
+	assert.Equal(t, LicenseOk, File(fileName, content, license, &Options{Replace: true}, handler))
+
+	// Allowed licenses are never duplicates (so they are not removed with -r), while the old headers
+	// of the mappings are
+	allowed := "/* Copyright Acme Corp. All rights reserved. */"
+	content = allowed + "\n\n" + license + "\n\npackage main\n"
+	licenses := &licenseSet{target: license, allowed: []allowedLicense{{name: "acme", header: allowed}}}
+	assert.Equal(t, LicenseOk, fileOperation(fileName, content, licenses, &Options{Replace: true}, handler).Action)
+	mappings, err := ParseMappings([]byte(`[{"name": "acme", "old_regex": "Acme Corp"}]`))
+	assert.Nil(t, err)
+	assert.Equal(t, DuplicateHeader, File(fileName, content, license, &Options{Mappings: mappings}, handler))
+	assert.Equal(t, LicenseOk, File(fileName, content, license, &Options{}, handler))
+
+	// Licenses after the leading comments are not duplicates
+	content = license + "\n\npackage main\n\n" + oldLicense + "\n"
+	assert.Equal(t, LicenseOk, File(fileName, content, license, &Options{}, handler))
+
+	// The leading comments are collapsed to a single target license with -r
+	options := &Options{Replace: true}
+	content = license + "\n\n" + license + "\n\npackage main\n"
+	handler.On("WriteFile", fileName, []byte(fixed)).Return(nil).Once()
+	assert.Equal(t, DuplicateFixed, File(fileName, content, license, options, handler))
+	handler.AssertExpectations(t)
+
+	content = oldLicense + "\n" + license + "\n\n// Package main does things\npackage main\n"
+	handler.On("WriteFile", fileName, []byte(license+"\n\n// Package main does things\npackage main\n")).Return(nil).Once()
+	assert.Equal(t, DuplicateFixed, File(fileName, content, license, options, handler))
+	handler.AssertExpectations(t)
+
+	// The preamble is kept
+	fileName = "main.py"
+	license = "# Copyright 2024 The Author"
+	content = "#!/usr/bin/env python\n\n# Copyright 2019 The Author\n\n" + license + "\n\nprint()\n"
+	handler.On("WriteFile", fileName, []byte("#!/usr/bin/env python\n\n"+license+"\n\nprint()\n")).Return(nil).Once()
+	assert.Equal(t, DuplicateFixed, File(fileName, content, license, options, handler))
+	handler.AssertExpectations(t)
+
+	// Errors writing the file are reported
+	content = license + "\n\n" + license + "\n\nprint()\n"
+	handler.On("WriteFile", fileName, []byte(license+"\n\nprint()\n")).Return(errors.New("error")).Once()
+	assert.Equal(t, OperationError, File(fileName, content, license, options, handler))
+	handler.AssertExpectations(t)
+}
@@ -0,0 +1,56 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import "regexp"
+
+// DefaultGeneratedMarkers are the markers of the generated files used when no markers are provided in the options:
+// the standard Go "// Code generated ... DO NOT EDIT." line, @generated and .NET's <auto-generated> tag
+var DefaultGeneratedMarkers = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.\r?$`),
+	regexp.MustCompile(`@generated\b`),
+	regexp.MustCompile(`<auto-generated`),
+}
+
+// generatedMarkers returns the markers that identify the generated files. An empty (but not nil)
+// list in the options disables the detection.
+func (o *Options) generatedMarkers() []*regexp.Regexp {
+	if o.GeneratedMarkers == nil {
+		return DefaultGeneratedMarkers
+	}
+	return o.GeneratedMarkers
+}
+
+// isGenerated returns true if any of the generation markers is found in the leading comments of the
+// content, which are the only ones that can contain them (e.g. the Go marker must appear before the
+// first non-comment text of the file)
+func (o *Options) isGenerated(lang *Language, content string) bool {
+	leading := content[:leadingCommentsEnd(lang, content)]
+	for _, marker := range o.generatedMarkers() {
+		if marker.MatchString(leading) {
+			return true
+		}
+	}
+	return false
+}
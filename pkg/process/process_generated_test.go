@@ -0,0 +1,73 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsGenerated(t *testing.T) {
+	options := &Options{}
+	golang := DefaultLanguages().Find("main.go")
+	assert.True(t, options.isGenerated(golang, "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage main\n"))
+	assert.True(t, options.isGenerated(golang, "// Copyright 2020 The Author\n\n// Code generated by stringer; DO NOT EDIT.\r\n\npackage main\n"))
+	assert.True(t, options.isGenerated(golang, "/*\n * @generated by some tool\n */\npackage main\n"))
+	assert.False(t, options.isGenerated(golang, "// Code generated by hand\n\npackage main\n"))
+
+	// The markers are only searched in the leading comments
+	assert.False(t, options.isGenerated(golang, "package main\n\n// Code generated by stringer; DO NOT EDIT.\n"))
+
+	python := DefaultLanguages().Find("main.py")
+	assert.True(t, options.isGenerated(python, "#!/usr/bin/env python\n# @generated\nprint()\n"))
+
+	// Custom markers replace the default ones
+	options = &Options{GeneratedMarkers: []*regexp.Regexp{regexp.MustCompile(`(?i)generated by protoc`)}}
+	assert.True(t, options.isGenerated(golang, "// Generated by protoc\npackage main\n"))
+	assert.False(t, options.isGenerated(golang, "// @generated\npackage main\n"))
+
+	// The detection is disabled with an empty list of markers
+	options = &Options{GeneratedMarkers: []*regexp.Regexp{}}
+	assert.False(t, options.isGenerated(golang, "// Code generated by stringer; DO NOT EDIT.\npackage main\n"))
+}
+
+func TestFile_Generated(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	content := "// Code generated by stringer; DO NOT EDIT.\n\npackage main\n"
+
+	// Generated files are never changed
+	assert.Equal(t, SkippedGenerated, File(fileName, content, testTargetLicenseHeader, &Options{Add: true, Replace: true}, handler))
+	assert.Equal(t, SkippedGenerated, File(fileName, content, "", &Options{RemoveAny: true}, handler))
+
+	// Unless the detection is disabled
+	options := &Options{Add: true, GeneratedMarkers: []*regexp.Regexp{}}
+	expected := strings.TrimSpace(testTargetLicenseHeader) + "\n\n" + content
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	assert.Equal(t, LicenseAdded, File(fileName, content, testTargetLicenseHeader, options, handler))
+	handler.AssertExpectations(t)
+}
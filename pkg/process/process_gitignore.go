@@ -0,0 +1,193 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// LicenseignoreFile is the name of the files with gitignore syntax that contain the paths ignored by the tool,
+// which are discovered automatically in every directory of the project
+const LicenseignoreFile = ".licenseignore"
+
+// ignorePattern is one of the patterns of a file with gitignore syntax (such as .gitignore)
+type ignorePattern struct {
+	// base is the directory of the file that contains the pattern relative to the root of the project
+	base     string
+	segments []string
+	negated  bool
+	anchored bool
+	dirOnly  bool
+	// source is the path of the file that contains the pattern
+	source string
+}
+
+// ignoreList are the patterns of the ignore files found while walking the project. The patterns of
+// the deeper files are added after the ones of their parents, so they take precedence over them.
+type ignoreList struct {
+	// prefix is the path of the root of the project relative to the root of its git repository (when
+	// the ignore files of the repository are loaded), which is where the bases of the patterns start
+	prefix   string
+	patterns []*ignorePattern
+}
+
+// load adds the patterns of the ignore file (if it exists) found in the base directory (relative to
+// the root of the project)
+func (l *ignoreList) load(h fileHandler, path, base string) {
+	l.loadFile(h, path, filepath.Join(l.prefix, base))
+}
+
+// loadFile adds the patterns of the ignore file (if it exists) found in the base directory (relative
+// to the root of the repository)
+func (l *ignoreList) loadFile(h fileHandler, path, base string) {
+	data, err := h.ReadFile(path)
+	if err != nil {
+		return
+	}
+	l.patterns = append(l.patterns, parseIgnoreFile(string(data), base, path)...)
+}
+
+// loadRepository finds the root of the git repository that contains the root of the project (which
+// may be above it) and adds the patterns of its .git/info/exclude file and of the .gitignore files of
+// the directories between both roots. Nothing is loaded if the project is not inside a repository.
+func (l *ignoreList) loadRepository(h fileHandler, root string) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return
+	}
+	// names are the directories from the root of the repository to the root of the project
+	var names []string
+	dir := root
+	for !isGitRepository(h, dir) {
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return
+		}
+		names = append([]string{filepath.Base(abs)}, names...)
+		abs, dir = parent, filepath.Join(dir, "..")
+	}
+	l.prefix = filepath.Join(names...)
+	l.loadFile(h, gitExcludePath(dir), "")
+	// The .gitignore file of the root of the project is loaded while walking it
+	base := ""
+	for _, name := range names {
+		l.loadFile(h, filepath.Join(dir, ".gitignore"), base)
+		dir, base = filepath.Join(dir, name), filepath.Join(base, name)
+	}
+}
+
+// match returns the last pattern that matches the path (relative to the root of the project) if it
+// makes the path ignored, or nil if the path is not ignored
+func (l *ignoreList) match(path string, isDir bool) *ignorePattern {
+	path = filepath.Join(l.prefix, path)
+	var match *ignorePattern
+	for _, pattern := range l.patterns {
+		if pattern.matches(path, isDir) {
+			match = pattern
+		}
+	}
+	if match == nil || match.negated {
+		return nil
+	}
+	return match
+}
+
+// parseIgnoreFile returns the patterns of the content of a file with gitignore syntax
+func parseIgnoreFile(content, base, source string) []*ignorePattern {
+	if base == "." {
+		base = ""
+	}
+	var patterns []*ignorePattern
+	for _, line := range strings.Split(content, "\n") {
+		if pattern := parseIgnorePattern(strings.TrimSuffix(line, "\r"), base, source); pattern != nil {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// parseIgnorePattern returns the pattern of one line of a file with gitignore syntax, or nil if the
+// line is blank or a comment
+func parseIgnorePattern(line, base, source string) *ignorePattern {
+	// Trailing spaces are ignored unless they are escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	pattern := &ignorePattern{base: base, source: source}
+	if strings.HasPrefix(line, "!") {
+		pattern.negated, line = true, line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly, line = true, strings.TrimRight(line, "/")
+	}
+	if len(line) == 0 {
+		return nil
+	}
+	// The patterns with a slash at the beginning or in the middle are relative to the directory
+	// of the file, while the others match the name of the files and directories at any level
+	pattern.anchored = strings.Contains(line, "/")
+	pattern.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	return pattern
+}
+
+// matches returns true if the pattern matches the path (relative to the root of the project)
+func (p *ignorePattern) matches(path string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	segments := splitPath(path)
+	if len(p.base) > 0 {
+		base := splitPath(p.base)
+		if len(segments) <= len(base) || strings.Join(segments[:len(base)], "/") != strings.Join(base, "/") {
+			return false
+		}
+		segments = segments[len(base):]
+	}
+	if !p.anchored {
+		segments = segments[len(segments)-1:]
+	}
+	return matchSegments(p.segments, segments, false)
+}
+
+// gitExcludePath returns the path of the file with the patterns excluded by git in the repository
+// at the provided root
+func gitExcludePath(root string) string {
+	return filepath.Join(root, ".git", "info", "exclude")
+}
+
+// isGitRepository returns true if the directory is the root of a git repository, whose .git is either
+// a directory or a file (in worktrees and submodules)
+func isGitRepository(h fileHandler, dir string) bool {
+	if _, err := h.ReadFile(filepath.Join(dir, ".git", "HEAD")); err == nil {
+		return true
+	}
+	_, err := h.ReadFile(filepath.Join(dir, ".git"))
+	return err == nil
+}
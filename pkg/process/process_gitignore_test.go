@@ -0,0 +1,209 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseIgnoreFile(t *testing.T) {
+	content := "# comment\n\n*.log  \r\n!keep.log\nbuild/\n/dist\ndocs/**/*.html\n\\#file\n\\!file\nspace\\ \n"
+	patterns := parseIgnoreFile(content, "src", "src/.gitignore")
+	assert.Len(t, patterns, 8)
+
+	assert.Equal(t, &ignorePattern{base: "src", segments: []string{"*.log"}, source: "src/.gitignore"}, patterns[0])
+	assert.True(t, patterns[1].negated)
+	assert.Equal(t, []string{"keep.log"}, patterns[1].segments)
+	assert.True(t, patterns[2].dirOnly)
+	assert.False(t, patterns[2].anchored)
+	assert.True(t, patterns[3].anchored)
+	assert.Equal(t, []string{"dist"}, patterns[3].segments)
+	assert.Equal(t, []string{"docs", "**", "*.html"}, patterns[4].segments)
+	assert.Equal(t, []string{"#file"}, patterns[5].segments)
+	assert.Equal(t, []string{"!file"}, patterns[6].segments)
+	assert.False(t, patterns[6].negated)
+	assert.Equal(t, []string{"space\\ "}, patterns[7].segments)
+
+	assert.Empty(t, parseIgnoreFile("", ".", ".gitignore"))
+	assert.Equal(t, "", parseIgnoreFile("*.log", ".", ".gitignore")[0].base)
+}
+
+func TestIgnoreList(t *testing.T) {
+	ignores := &ignoreList{}
+	ignores.patterns = parseIgnoreFile("*.log\nbuild/\n/dist\ndocs/**/*.html\nspace\\ ", "", ".gitignore")
+
+	// Patterns without slashes match at any level
+	assert.NotNil(t, ignores.match("app.log", false))
+	assert.NotNil(t, ignores.match("src/logs/app.log", false))
+	assert.Nil(t, ignores.match("app.log.go", false))
+
+	// Directory-only patterns
+	assert.NotNil(t, ignores.match("src/build", true))
+	assert.Nil(t, ignores.match("src/build", false))
+
+	// Anchored patterns
+	assert.NotNil(t, ignores.match("dist", true))
+	assert.Nil(t, ignores.match("src/dist", true))
+	assert.NotNil(t, ignores.match("docs/index.html", false))
+	assert.NotNil(t, ignores.match("docs/api/v1/index.html", false))
+	assert.Nil(t, ignores.match("src/docs/index.html", false))
+
+	// Escaped trailing spaces
+	assert.NotNil(t, ignores.match("space ", false))
+
+	// The patterns of deeper files only apply inside their directory and take precedence
+	ignores.patterns = append(ignores.patterns, parseIgnoreFile("!important.log\n/generated.go", "src", "src/.gitignore")...)
+	assert.Nil(t, ignores.match("src/important.log", false))
+	assert.NotNil(t, ignores.match("important.log", false))
+	assert.NotNil(t, ignores.match("src/generated.go", false))
+	assert.Nil(t, ignores.match("generated.go", false))
+	assert.Nil(t, ignores.match("src/pkg/generated.go", false))
+	assert.Equal(t, "src/.gitignore", ignores.match("src/generated.go", false).source)
+}
+
+func TestFiles_Gitignore(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{
+		LicensePath: "license.txt",
+		Path:        "project",
+		Extensions:  []string{".go"},
+		Gitignore:   true,
+	}
+	handler.pathsToWalk = []string{
+		"project/",
+		"project/.git/",
+		"project/.git/hooks.go",
+		"project/main.go",
+		"project/local.go",
+		"project/build/",
+		"project/build/out.go",
+		"project/src/",
+		"project/src/lib.go",
+		"project/src/lib_gen.go",
+		"project/src/types_gen.go",
+	}
+	handler.On("WalkDir", options.Path, mock.Anything).Return(nil).Once()
+	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
+	handler.On("ReadFile", "project/.git/HEAD").Return([]byte("ref: refs/heads/main\n"), nil).Once()
+	handler.On("ReadFile", "project/.git/info/exclude").Return([]byte("local.go\n"), nil).Once()
+	handler.On("ReadFile", "project/.gitignore").Return([]byte("build/\n*_gen.go\n"), nil).Once()
+	handler.On("ReadFile", "project/src/.gitignore").Return([]byte{}, fs.ErrNotExist).Once()
+	handler.On("ReadFile", "project/.licenseignore").Return([]byte{}, fs.ErrNotExist).Once()
+	handler.On("ReadFile", "project/src/.licenseignore").Return([]byte{}, fs.ErrNotExist).Once()
+	handler.On("ReadFile", "project/main.go").Return([]byte(testFileWithTargetLicense), nil).Once()
+	handler.On("ReadFile", "project/src/lib.go").Return([]byte(testFileWithTargetLicense), nil).Once()
+
+	stats, err := Files(options, handler)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"project/main.go", "project/src/lib.go"}, stats.Files[LicenseOk])
+	assert.Equal(t, map[string]string{
+		"project/local.go":         "project/.git/info/exclude",
+		"project/build":            "project/.gitignore",
+		"project/src/lib_gen.go":   "project/.gitignore",
+		"project/src/types_gen.go": "project/.gitignore",
+	}, stats.Ignored)
+	handler.AssertExpectations(t)
+}
+
+func TestFiles_GitignoreAboveProject(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{
+		LicensePath: "license.txt",
+		Path:        "repo/project/src",
+		Extensions:  []string{".go"},
+		Gitignore:   true,
+	}
+	handler.pathsToWalk = []string{
+		"repo/project/src/",
+		"repo/project/src/main.go",
+		"repo/project/src/local.go",
+		"repo/project/src/lib_gen.go",
+		"repo/project/src/build/",
+		"repo/project/src/build/out.go",
+	}
+	handler.On("WalkDir", options.Path, mock.Anything).Return(nil).Once()
+	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
+	for _, dir := range []string{"repo/project/src", "repo/project"} {
+		handler.On("ReadFile", dir+"/.git/HEAD").Return([]byte{}, fs.ErrNotExist).Once()
+		handler.On("ReadFile", dir+"/.git").Return([]byte{}, fs.ErrNotExist).Once()
+	}
+	handler.On("ReadFile", "repo/.git/HEAD").Return([]byte("ref: refs/heads/main\n"), nil).Once()
+	handler.On("ReadFile", "repo/.git/info/exclude").Return([]byte("/project/src/local.go\n"), nil).Once()
+	handler.On("ReadFile", "repo/.gitignore").Return([]byte("*_gen.go\n"), nil).Once()
+	handler.On("ReadFile", "repo/project/.gitignore").Return([]byte("src/build/\n"), nil).Once()
+	handler.On("ReadFile", "repo/project/src/.gitignore").Return([]byte{}, fs.ErrNotExist).Once()
+	handler.On("ReadFile", "repo/project/src/.licenseignore").Return([]byte{}, fs.ErrNotExist).Once()
+	handler.On("ReadFile", "repo/project/src/main.go").Return([]byte(testFileWithTargetLicense), nil).Once()
+
+	// The ignore files of the repository above the project are matched from the root of the repository
+	stats, err := Files(options, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"repo/project/src/main.go"}, stats.Files[LicenseOk])
+	assert.Equal(t, map[string]string{
+		"repo/project/src/local.go":   "repo/.git/info/exclude",
+		"repo/project/src/lib_gen.go": "repo/.gitignore",
+		"repo/project/src/build":      "repo/project/.gitignore",
+	}, stats.Ignored)
+	handler.AssertExpectations(t)
+}
+
+func TestFiles_Licenseignore(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{
+		LicensePath: "license.txt",
+		Path:        "project",
+		Extensions:  []string{".go"},
+	}
+	handler.pathsToWalk = []string{
+		"project/",
+		"project/main.go",
+		"project/fixtures/",
+		"project/fixtures/fixture.go",
+		"project/src/",
+		"project/src/lib.go",
+		"project/src/snippet.go",
+		"project/src/snippet.txt",
+	}
+	handler.On("WalkDir", options.Path, mock.Anything).Return(nil).Once()
+	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
+	handler.On("ReadFile", "project/.licenseignore").Return([]byte("/fixtures\n"), nil).Once()
+	handler.On("ReadFile", "project/src/.licenseignore").Return([]byte("snippet.*\n"), nil).Once()
+	handler.On("ReadFile", "project/main.go").Return([]byte(testFileWithTargetLicense), nil).Once()
+	handler.On("ReadFile", "project/src/lib.go").Return([]byte(testFileWithTargetLicense), nil).Once()
+
+	// The .gitignore files are only read with options.Gitignore, and only the ignored files that
+	// would have been processed otherwise are reported
+	stats, err := Files(options, handler)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"project/main.go", "project/src/lib.go"}, stats.Files[LicenseOk])
+	assert.Equal(t, map[string]string{
+		"project/fixtures":       "project/.licenseignore",
+		"project/src/snippet.go": "project/src/.licenseignore",
+	}, stats.Ignored)
+	handler.AssertExpectations(t)
+}
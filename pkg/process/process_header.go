@@ -30,27 +30,198 @@ import (
 
 var DefaultRegex *regexp.Regexp = regexp.MustCompile(`/\*([^*]|[\r\n]|(\*+([^*/]|[\r\n])))*\*+/`)
 
-// containsLicenseHeader returns true if the content contains the words license or copyright in a header comment
-func containsLicenseHeader(re *regexp.Regexp, content string) bool {
-	header := strings.ToLower(extractHeader(re, content))
-	containsCopyright := strings.Contains(header, "copyright")
-	containsLicense := strings.Contains(header, "license")
-	return containsCopyright || containsLicense
+// HeaderMatch is the region of the content of a file detected as its license header
+type HeaderMatch struct {
+	// Start is the byte offset of the beginning of the header
+	Start int
+	// End is the byte offset right after the end of the header
+	End int
+	// Text is the content between Start and End
+	Text string
 }
 
-// extractHeader returns the first block comment of the content (if any). Empty string otherwise.
-func extractHeader(re *regexp.Regexp, content string) string {
-	return re.FindString(content)
+// DetectHeader returns the license header found in the leading comments of the content (or nil if
+// there is none) using the comment syntax of the file's language. The offsets of the match are the
+// region of the content that is rewritten when the license is replaced.
+func DetectHeader(path string, content string, options *Options) *HeaderMatch {
+	return options.licenseHeader(options.fileLanguage(path, content), content)
 }
 
-// insertHeader inserts the provided header at the beginning of the content separated by one empty line
-func insertHeader(content, header string) string {
-	return strings.TrimSpace(header) + "\n\n" + strings.TrimLeft(content, "\n")
+// containsLicenseHeader returns true if the header comment of the content is a license header
+func containsLicenseHeader(lang *Language, content string, options *Options) bool {
+	return options.licenseHeader(lang, content) != nil
 }
 
-// replaceHeader removes the current header and inserts the provided one
-func replaceHeader(re *regexp.Regexp, content, header string) (res string) {
-	oldHeader := extractHeader(re, content)
-	res = strings.ReplaceAll(content, strings.TrimSpace(oldHeader), strings.TrimSpace(header))
-	return res
+// licenseHeader returns the header comment of the content if it is a license header according to
+// the detection rules of the options (by default, if it contains the words license or copyright).
+// The line comments attached to the code are never license headers unless a custom header regex
+// has been provided.
+func (o *Options) licenseHeader(lang *Language, content string) *HeaderMatch {
+	match := findHeader(lang, content)
+	if match == nil || !o.isLicenseHeader(match.Text) {
+		return nil
+	}
+	if o.HeaderRegex == nil && lang.isDocComment(content, match) {
+		return nil
+	}
+	return match
+}
+
+// isDocComment returns true if the header is a run of line comments directly followed by code (with
+// no blank line in between), which documents that code (e.g. the doc comment of a Go package)
+func (l *Language) isDocComment(content string, header *HeaderMatch) bool {
+	text := strings.TrimSpace(header.Text)
+	if len(l.LinePrefix) == 0 || !strings.HasPrefix(text, l.LinePrefix) {
+		return false
+	}
+	if len(l.BlockStart) > 0 && strings.HasPrefix(text, l.BlockStart) {
+		return false
+	}
+	rest := strings.TrimPrefix(content[header.End:], "\n")
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i]
+	}
+	return len(strings.TrimSpace(rest)) > 0
+}
+
+// extractHeader returns the comment found right after the preamble of the content (if any). Empty string otherwise.
+func extractHeader(lang *Language, content string) string {
+	match := findHeader(lang, content)
+	if match == nil {
+		return ""
+	}
+	return match.Text
+}
+
+// findHeader returns the comment found right after the preamble of the content (if any)
+func findHeader(lang *Language, content string) *HeaderMatch {
+	start := lang.preambleEnd(content)
+	loc := lang.headerRegex.FindStringIndex(content[start:])
+	if loc == nil || len(strings.TrimSpace(content[start:start+loc[0]])) > 0 {
+		return nil
+	}
+	return &HeaderMatch{Start: start + loc[0], End: start + loc[1], Text: content[start+loc[0] : start+loc[1]]}
+}
+
+// isLeading returns true if the position is part of the leading comments of the content, which
+// are the only ones that can contain the license header
+func isLeading(lang *Language, content string, pos int) bool {
+	return pos >= lang.preambleEnd(content) && pos <= leadingCommentsEnd(lang, content)
+}
+
+// insertHeader inserts the provided header right after the preamble of the content (if any)
+// separated by the blank lines defined in spacing
+func insertHeader(lang *Language, content, header string, spacing Spacing) string {
+	bom := ""
+	if strings.HasPrefix(content, byteOrderMark) {
+		bom, content = byteOrderMark, content[len(byteOrderMark):]
+	}
+	end := lang.preambleEnd(content)
+	preamble := strings.TrimRight(content[:end], "\r\n")
+	code := strings.TrimLeft(content[end:], "\r\n")
+
+	res := strings.TrimSpace(header) + strings.Repeat("\n", spacing.After+1) + code
+	if len(preamble) > 0 {
+		res = preamble + strings.Repeat("\n", spacing.Before+1) + res
+	}
+	return bom + res
+}
+
+// replaceHeader replaces the region of the content matched as its header by the provided one. If there
+// is no match, the header is inserted.
+func replaceHeader(lang *Language, content string, match *HeaderMatch, header string, spacing Spacing) string {
+	if match != nil {
+		content = content[:match.Start] + content[match.End:]
+	}
+	return insertHeader(lang, content, header, spacing)
+}
+
+// isStandaloneComment returns true if the text between start and end is a complete comment (according
+// to the header regex of the language) that shares its lines with nothing but whitespace, which is the
+// only kind of misplaced license that can be moved without breaking the code (e.g. a string literal)
+func isStandaloneComment(lang *Language, content string, start, end int) bool {
+	lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+	lineEnd := len(content)
+	if i := strings.IndexByte(content[end:], '\n'); i >= 0 {
+		lineEnd = end + i
+	}
+	if len(strings.TrimSpace(content[lineStart:start])) > 0 || len(strings.TrimSpace(content[end:lineEnd])) > 0 {
+		return false
+	}
+	loc := lang.headerRegex.FindStringIndex(content[lineStart:])
+	return loc != nil && lineStart+loc[0] <= start && lineStart+loc[1] >= end
+}
+
+// moveHeader removes the license header found between start and end and places it at the
+// top of the content (replacing the current header, if any)
+func moveHeader(lang *Language, content string, start, end int, options *Options) string {
+	header := content[start:end]
+	content = removeRegion(content, start, end)
+	return replaceHeader(lang, content, options.licenseHeader(lang, content), header, options.spacing())
+}
+
+// removeRegion removes the text between start and end along with the rest of its last line
+// if it is empty, without leaving more than one empty line in its place
+func removeRegion(content string, start, end int) string {
+	bom := ""
+	if strings.HasPrefix(content, byteOrderMark) && start >= len(byteOrderMark) {
+		bom = byteOrderMark
+	}
+	before := strings.TrimRight(content[len(bom):start], " \t")
+	after := strings.TrimLeft(content[end:], " \t")
+	after = strings.TrimPrefix(strings.TrimPrefix(after, "\r"), "\n")
+
+	if len(strings.TrimSpace(after)) == 0 {
+		if len(strings.TrimSpace(before)) == 0 {
+			return bom
+		}
+		return bom + strings.TrimRight(before, "\r\n") + "\n"
+	}
+	if len(strings.TrimSpace(before)) == 0 {
+		return bom + strings.TrimLeft(after, "\r\n")
+	}
+	if strings.HasSuffix(before, "\n\n") || strings.HasSuffix(before, "\r\n\r\n") {
+		after = strings.TrimLeft(after, "\r\n")
+	}
+	return bom + before + after
+}
+
+// leadingCommentsEnd returns the position of the content right after the preamble and the
+// comments (and empty lines) that follow it
+func leadingCommentsEnd(lang *Language, content string) int {
+	inBlock := false
+	offset := lang.preambleEnd(content)
+	for offset < len(content) {
+		next := len(content)
+		if i := strings.IndexByte(content[offset:], '\n'); i >= 0 {
+			next = offset + i + 1
+		}
+		line := content[offset:next]
+		trimmed := strings.TrimSpace(line)
+		blockEnd := -1
+		switch {
+		case inBlock:
+			blockEnd = strings.Index(line, lang.BlockEnd)
+		case len(trimmed) == 0:
+		case len(lang.LinePrefix) > 0 && strings.HasPrefix(trimmed, lang.LinePrefix):
+		case len(lang.BlockStart) > 0 && strings.HasPrefix(trimmed, lang.BlockStart):
+			inBlock = true
+			start := strings.Index(line, lang.BlockStart) + len(lang.BlockStart)
+			if i := strings.Index(line[start:], lang.BlockEnd); i >= 0 {
+				blockEnd = start + i
+			}
+		default:
+			return offset
+		}
+		if blockEnd >= 0 {
+			inBlock = false
+			// code after the end of a block comment is not part of the leading comments
+			end := blockEnd + len(lang.BlockEnd)
+			if len(strings.TrimSpace(line[end:])) > 0 {
+				return offset + end
+			}
+		}
+		offset = next
+	}
+	return offset
 }
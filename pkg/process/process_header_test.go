@@ -32,53 +32,108 @@ import (
 )
 
 func TestContainsLicenseHeader(t *testing.T) {
-	assert.True(t, containsLicenseHeader(DefaultRegex, testFileWithTargetLicense))
-	assert.True(t, containsLicenseHeader(DefaultRegex, testFileWithDifferentLicense))
-	assert.False(t, containsLicenseHeader(DefaultRegex, testFileWithoutLicense))
+	assert.True(t, containsLicenseHeader(defaultLanguage, testFileWithTargetLicense, &Options{}))
+	assert.True(t, containsLicenseHeader(defaultLanguage, testFileWithDifferentLicense, &Options{}))
+	assert.False(t, containsLicenseHeader(defaultLanguage, testFileWithoutLicense, &Options{}))
 }
 
 func TestExtractHeader(t *testing.T) {
 	expected := strings.TrimSpace(testTargetLicenseHeader)
 
 	input := testFileWithTargetLicense
-	output := extractHeader(DefaultRegex, input)
+	output := extractHeader(defaultLanguage, input)
 	assert.True(t, output == expected)
 
 	// Check that build tags are not included in the extracted header
+	golang := DefaultLanguages().Find("main.go")
 	input = testFileWithBuildTagsAndTargetLicense
-	output = extractHeader(DefaultRegex, input)
+	output = extractHeader(golang, input)
 	assert.True(t, output == expected)
 
 	// Check that only the header gets extracted
 	input = testFileWithTargetLicenseAndExtraComments
-	output = extractHeader(DefaultRegex, input)
+	output = extractHeader(golang, input)
 	assert.True(t, output == expected)
 
+	// Check that comments after the code are not extracted
+	input = "package main\n\n/* copyright */\n"
+	output = extractHeader(golang, input)
+	assert.True(t, output == "")
+
 	expected = "/* copyright */"
 	input = "/* copyright */\nlorem ipsum dolor sit amet"
-	output = extractHeader(DefaultRegex, input)
+	output = extractHeader(defaultLanguage, input)
 	assert.True(t, output == expected)
 
 	// Check non-default headers
 	expected = strings.TrimSpace(testPythonTargetLicense)
-	pyRegex := regexp.MustCompile(`"""(.|[\r\n])*"""`)
+	pyLanguage := defaultLanguage.withHeaderRegex(regexp.MustCompile(`"""(.|[\r\n])*"""`))
 
-	output = extractHeader(pyRegex, testFileWithPythonTargetLicense)
+	output = extractHeader(pyLanguage, testFileWithPythonTargetLicense)
 	assert.True(t, output == expected)
 
-	output = extractHeader(pyRegex, testFileWithDifferentPythonTargetLicense)
+	output = extractHeader(pyLanguage, testFileWithDifferentPythonTargetLicense)
 	assert.True(t, output != expected)
 
-	output = extractHeader(pyRegex, testFileWithoutPythonTargetLicense)
+	output = extractHeader(pyLanguage, testFileWithoutPythonTargetLicense)
 	assert.True(t, output != expected)
 }
 
+func TestExtractHeader_LineComments(t *testing.T) {
+	languages := DefaultLanguages()
+
+	// A run of line comments ends at the first blank line
+	content := "// Copyright (c) 2020 The Author\n// Licensed under the MIT License\n\n// Package main\npackage main\n"
+	assert.Equal(t, "// Copyright (c) 2020 The Author\n// Licensed under the MIT License", extractHeader(languages.Find("main.go"), content))
+
+	// A run of line comments ends at the first non-comment line
+	content = "# Copyright (c) 2020 The Author\n#\n# Licensed under the MIT License\nname: build\n# comment\n"
+	assert.Equal(t, "# Copyright (c) 2020 The Author\n#\n# Licensed under the MIT License", extractHeader(languages.Find("ci.yml"), content))
+
+	// Indented line comments are part of the run
+	content = "-- Copyright (c) 2020 The Author\n  -- Licensed under the MIT License\nSELECT 1;\n"
+	assert.Equal(t, "-- Copyright (c) 2020 The Author\n  -- Licensed under the MIT License", extractHeader(languages.Find("query.sql"), content))
+
+	// Block comments are still detected in languages with both comment styles
+	content = "/* Copyright (c) 2020 The Author */\n// Package main\npackage main\n"
+	assert.Equal(t, "/* Copyright (c) 2020 The Author */", extractHeader(languages.Find("main.go"), content))
+}
+
 func TestInsertHeader(t *testing.T) {
 	expected := testFileWithTargetLicense
 	input := testFileWithoutLicense
 	header := testTargetLicenseHeader
-	output := insertHeader(input, header)
+	output := insertHeader(defaultLanguage, input, header, DefaultSpacing)
 	assert.True(t, output == expected)
+
+	// Check that the preamble stays before the header
+	languages := DefaultLanguages()
+	header = "# Copyright"
+	tests := []struct {
+		path     string
+		content  string
+		expected string
+	}{
+		{"main.py", "#!/usr/bin/env python\nprint()\n", "#!/usr/bin/env python\n\n# Copyright\n\nprint()\n"},
+		{"main.py", "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n\nprint()\n", "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n\n# Copyright\n\nprint()\n"},
+		{"main.py", "# vim: set fileencoding=latin-1 :\nprint()\n", "# vim: set fileencoding=latin-1 :\n\n# Copyright\n\nprint()\n"},
+		// Encoding declarations are only valid in the first two lines
+		{"main.py", "#!/usr/bin/env python\n\n# coding: utf-8\nprint()\n", "#!/usr/bin/env python\n\n# Copyright\n\n# coding: utf-8\nprint()\n"},
+		{"main.py", "# coding:\nprint()\n", "# Copyright\n\n# coding:\nprint()\n"},
+		{"main.py", "\ufeffprint()\n", "\ufeff# Copyright\n\nprint()\n"},
+		{"main.py", "\ufeff#!/usr/bin/env python\nprint()\n", "\ufeff#!/usr/bin/env python\n\n# Copyright\n\nprint()\n"},
+		{"index.php", "<?php\necho 1;\n", "<?php\n\n# Copyright\n\necho 1;\n"},
+		{"pom.xml", "<?xml version=\"1.0\"?>\n<project/>\n", "<?xml version=\"1.0\"?>\n\n# Copyright\n\n<project/>\n"},
+	}
+	for _, test := range tests {
+		output = insertHeader(languages.Find(test.path), test.content, header, DefaultSpacing)
+		assert.Equal(t, test.expected, output, test.path)
+	}
+
+	// Check custom spacing
+	python := languages.Find("main.py")
+	output = insertHeader(python, "#!/usr/bin/env python\nprint()\n", header, Spacing{Before: 0, After: 2})
+	assert.Equal(t, "#!/usr/bin/env python\n# Copyright\n\n\nprint()\n", output)
 }
 
 func TestReplaceHeader(t *testing.T) {
@@ -86,12 +141,122 @@ func TestReplaceHeader(t *testing.T) {
 
 	expected := testFileWithTargetLicense
 	input := testFileWithDifferentLicense
-	output := replaceHeader(DefaultRegex, input, header)
+	output := replaceHeader(defaultLanguage, input, findHeader(defaultLanguage, input), header, DefaultSpacing)
 	assert.True(t, output == expected)
 
 	// Check that build tags are not removed after replacing license
 	expected = testFileWithBuildTagsAndTargetLicense
 	input = testFileWithBuildTagsAndDifferentLicense
-	output = replaceHeader(DefaultRegex, input, header)
+	golang := DefaultLanguages().Find("main.go")
+	output = replaceHeader(golang, input, findHeader(golang, input), header, DefaultSpacing)
 	assert.True(t, output == expected)
+
+	// Check that the preamble and the spacing are respected after replacing license
+	python := DefaultLanguages().Find("main.py")
+	input = "#!/usr/bin/env python\n# Copyright 2019 Another Author\nprint()\n"
+	output = replaceHeader(python, input, findHeader(python, input), "# Copyright 2020 The Author", DefaultSpacing)
+	assert.Equal(t, "#!/usr/bin/env python\n\n# Copyright 2020 The Author\n\nprint()\n", output)
+
+	// Check that exactly the line comment block is replaced
+	input = "// Copyright 2019 Another Author\n// All rights reserved.\n\n// Package main\npackage main\n"
+	output = replaceHeader(golang, input, findHeader(golang, input), "// Copyright 2020 The Author", DefaultSpacing)
+	assert.Equal(t, "// Copyright 2020 The Author\n\n// Package main\npackage main\n", output)
+
+	// Check that other copies of the header are not replaced
+	input = "/* Copyright 2019 Another Author */\n\npackage main\n\nconst header = `/* Copyright 2019 Another Author */`\n"
+	output = replaceHeader(golang, input, findHeader(golang, input), "/* Copyright 2020 The Author */", DefaultSpacing)
+	assert.Equal(t, "/* Copyright 2020 The Author */\n\npackage main\n\nconst header = `/* Copyright 2019 Another Author */`\n", output)
+}
+
+func TestDetectHeader(t *testing.T) {
+	options := &Options{}
+	content := "#!/usr/bin/env python\n# Copyright 2019 The Author\n\n# Not a header\nprint()\n"
+	match := DetectHeader("main.py", content, options)
+	assert.Equal(t, &HeaderMatch{Start: 22, End: 49, Text: "# Copyright 2019 The Author"}, match)
+	assert.Equal(t, match.Text, content[match.Start:match.End])
+
+	// Comments without license keywords are not license headers
+	assert.Nil(t, DetectHeader("main.py", "# Hello\nprint()\n", options))
+	assert.Nil(t, DetectHeader("main.py", "print()\n", options))
+}
+
+func TestLeadingCommentsEnd(t *testing.T) {
+	golang := DefaultLanguages().Find("main.go")
+	content := "//go:build tools\n\n/*\n * License\n */\n\n// Package tools\npackage tools\n"
+	assert.Equal(t, strings.Index(content, "package"), leadingCommentsEnd(golang, content))
+
+	content = "/* License */ package main\n"
+	assert.Equal(t, len("/* License */"), leadingCommentsEnd(golang, content))
+
+	content = "// License\n"
+	assert.Equal(t, len(content), leadingCommentsEnd(golang, content))
+
+	python := DefaultLanguages().Find("main.py")
+	content = "#!/usr/bin/env python\n# License\n\nprint('Hello')\n"
+	assert.Equal(t, strings.Index(content, "print"), leadingCommentsEnd(python, content))
+}
+
+func TestIsLeading(t *testing.T) {
+	golang := DefaultLanguages().Find("main.go")
+	content := "//go:build tools\n\n// Package tools\n/* License */\npackage tools\n\n/* License */\n"
+	assert.True(t, isLeading(golang, content, strings.Index(content, "/* License */")))
+	assert.False(t, isLeading(golang, content, strings.LastIndex(content, "/* License */")))
+	assert.False(t, isLeading(golang, content, 0))
+}
+
+func TestMoveHeader(t *testing.T) {
+	golang := DefaultLanguages().Find("main.go")
+
+	content := "package main\n\n/* Copyright (c) 2020 The Author */\n\nfunc main() {}\n"
+	start := strings.Index(content, "/*")
+	end := strings.Index(content, "*/") + 2
+	expected := "/* Copyright (c) 2020 The Author */\n\npackage main\n\nfunc main() {}\n"
+	assert.Equal(t, expected, moveHeader(golang, content, start, end, &Options{}))
+
+	// The current header is replaced by the moved one
+	content = "/* Copyright (c) 2019 Another Author */\n\npackage main\n\n/* Copyright (c) 2020 The Author */\n"
+	start = strings.LastIndex(content, "/*")
+	expected = "/* Copyright (c) 2020 The Author */\n\npackage main\n"
+	assert.Equal(t, expected, moveHeader(golang, content, start, len(content)-1, &Options{}))
+}
+
+func TestRemoveRegion(t *testing.T) {
+	content := "a\n\nremove\n\nb\n"
+	start := strings.Index(content, "remove")
+	assert.Equal(t, "a\n\nb\n", removeRegion(content, start, start+len("remove")))
+
+	content = "a\nremove\nb\n"
+	start = strings.Index(content, "remove")
+	assert.Equal(t, "a\nb\n", removeRegion(content, start, start+len("remove")))
+
+	content = "remove\n\nb\n"
+	assert.Equal(t, "b\n", removeRegion(content, 0, len("remove")))
+
+	content = "a\n\nremove\n"
+	start = strings.Index(content, "remove")
+	assert.Equal(t, "a\n", removeRegion(content, start, start+len("remove")))
+
+	assert.Equal(t, "", removeRegion("remove\n", 0, len("remove")))
+
+	// The byte order mark is kept
+	content = "\ufeffremove\n\nb\n"
+	start = len("\ufeff")
+	assert.Equal(t, "\ufeffb\n", removeRegion(content, start, start+len("remove")))
+}
+
+func TestIsStandaloneComment(t *testing.T) {
+	golang := DefaultLanguages().Find("main.go")
+	license := "/* Copyright 2020 The Author */"
+
+	content := "package main\n\n" + license + "\n"
+	assert.True(t, isStandaloneComment(golang, content, 14, 14+len(license)))
+
+	content = "package main\n\n  " + license + "  \r\nfunc main() {}\n"
+	assert.True(t, isStandaloneComment(golang, content, 16, 16+len(license)))
+
+	content = "package main\n\nconst s = `" + license + "`\n"
+	assert.False(t, isStandaloneComment(golang, content, 25, 25+len(license)))
+
+	content = "package main\n\nvar x = 1 " + license + "\n"
+	assert.False(t, isStandaloneComment(golang, content, 24, 24+len(license)))
 }
@@ -24,35 +24,135 @@ SOFTWARE.
 package process
 
 import (
-	"os"
+	"fmt"
+	"path"
 	"path/filepath"
-	"reflect"
 	"strings"
 )
 
-// shouldIgnoreExtension returns false only if the file's extension is one of the provided ones
+// shouldIgnoreExtension returns false only if the file's name ends with one of the provided extensions,
+// which are compared case insensitively and can contain several dots (e.g. .d.ts)
 func shouldIgnoreExtension(path string, extensions []string) bool {
-	fileExtension := filepath.Ext(path)
+	name := strings.ToLower(filepath.Base(path))
 	for _, ext := range extensions {
-		if fileExtension == ext {
+		if strings.HasSuffix(name, strings.ToLower(ext)) {
 			return false
 		}
 	}
 	return true
 }
 
-// shouldIgnore returns true if the path matches any of the paths to ignore
+// shouldIgnorePath returns true if the path matches any of the patterns of the paths to ignore and it
+// is not included again by a later negated pattern (starting with !). The path must be relative to the
+// root of the project, which is where the patterns starting with / are anchored. Otherwise, the patterns
+// can match any sequence of segments of the path, and the ones that match a directory also match all
+// the paths inside it.
+//
+// The segments of the patterns can contain the wildcards of path.Match (*, ? and character classes)
+// and ** matches any number of segments (e.g. **/testdata/** or **/*_mock.go).
 func shouldIgnorePath(path string, ignorePaths []string) bool {
-	pathSegments := strings.Split(path, string(os.PathSeparator))
-	for _, ignorePath := range ignorePaths {
-		ignorePathSegments := strings.Split(ignorePath, string(os.PathSeparator))
-		size := len(ignorePathSegments)
-		lastSegment := len(pathSegments) - size
-		for i := 0; i <= lastSegment; i++ {
-			if reflect.DeepEqual(pathSegments[i:i+size], ignorePathSegments) {
-				return true
+	segments := splitPath(path)
+	ignored := false
+	for _, pattern := range ignorePaths {
+		negated := strings.HasPrefix(pattern, "!")
+		// Only the patterns that could change the result are evaluated
+		if ignored != negated {
+			continue
+		}
+		if matchPath(strings.TrimPrefix(pattern, "!"), segments) {
+			ignored = !negated
+		}
+	}
+	return ignored
+}
+
+// shouldSkipDir returns true if the directory and everything inside it can be ignored without walking it,
+// which is not possible when there are negated patterns as they could include again some of its paths
+func shouldSkipDir(path string, ignorePaths []string) bool {
+	for _, pattern := range ignorePaths {
+		if strings.HasPrefix(pattern, "!") {
+			return false
+		}
+	}
+	return shouldIgnorePath(path, ignorePaths)
+}
+
+// ignoresPath returns true if the walked path must be ignored according to options.IgnorePaths. The
+// patterns are matched against the path relative to the root of the project and, for backward
+// compatibility, against the walked path itself (e.g. src/generated when the root of the project is src).
+func (o *Options) ignoresPath(path string) bool {
+	return shouldIgnorePath(relativePath(o.Path, path), o.IgnorePaths) || shouldIgnorePath(path, o.IgnorePaths)
+}
+
+// skipsDir returns true if the walked directory and everything inside it can be ignored without walking it
+// (see ignoresPath)
+func (o *Options) skipsDir(path string) bool {
+	return shouldSkipDir(relativePath(o.Path, path), o.IgnorePaths) || shouldSkipDir(path, o.IgnorePaths)
+}
+
+// ParseIgnorePaths returns the patterns of a comma separated list of paths to ignore validating their syntax
+func ParseIgnorePaths(value string) ([]string, error) {
+	var ignorePaths []string
+	for _, pattern := range strings.Split(value, ",") {
+		if len(pattern) == 0 {
+			continue
+		}
+		for _, segment := range splitPath(strings.TrimPrefix(pattern, "!")) {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
 			}
 		}
+		ignorePaths = append(ignorePaths, pattern)
+	}
+	return ignorePaths, nil
+}
+
+// relativePath returns the path relative to the root of the project (or the path itself if it is not inside it)
+func relativePath(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// splitPath returns the segments of a path (using / as separator on every platform)
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(filepath.ToSlash(path), "/"), "/")
+}
+
+// matchPath returns true if the pattern matches the segments of the path from the beginning (when it is
+// anchored with a leading /) or from any of them
+func matchPath(pattern string, segments []string) bool {
+	anchored := strings.HasPrefix(filepath.ToSlash(pattern), "/")
+	patternSegments := splitPath(pattern)
+	for i := 0; i < len(segments); i++ {
+		if matchSegments(patternSegments, segments[i:], true) {
+			return true
+		}
+		if anchored {
+			break
+		}
 	}
 	return false
 }
+
+// matchSegments returns true if the pattern segments match all the segments of the path or, when
+// prefix is true, its first segments
+func matchSegments(pattern, segments []string, prefix bool) bool {
+	if len(pattern) == 0 {
+		return prefix || len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:], prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], segments[0])
+	return err == nil && ok && matchSegments(pattern[1:], segments[1:], prefix)
+}
@@ -24,9 +24,11 @@ SOFTWARE.
 package process
 
 import (
+	"io/fs"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestShouldIgnoreExtension(t *testing.T) {
@@ -35,6 +37,12 @@ func TestShouldIgnoreExtension(t *testing.T) {
 	assert.False(t, shouldIgnoreExtension("readme.md", extensions))
 	assert.True(t, shouldIgnoreExtension("index.html", extensions))
 	assert.True(t, shouldIgnoreExtension("styles.css", extensions))
+
+	// Case insensitive and multi-dot extensions
+	assert.False(t, shouldIgnoreExtension("INDEX.JS", extensions))
+	assert.False(t, shouldIgnoreExtension("types/index.d.ts", []string{".d.ts"}))
+	assert.True(t, shouldIgnoreExtension("src/index.ts", []string{".d.ts"}))
+	assert.True(t, shouldIgnoreExtension("Makefile", extensions))
 }
 
 func TestShouldIgnoreFolder(t *testing.T) {
@@ -70,3 +78,110 @@ func TestFileShouldIgnoreUsesFullIgnoreList(t *testing.T) {
 	assert.True(t, shouldIgnorePath("node_modules/index.js", []string{"docs", "node_modules", "test"}))
 	assert.True(t, shouldIgnorePath("node_modules/index.js", []string{"docs", "test", "node_modules"}))
 }
+
+func TestShouldIgnoreGlob(t *testing.T) {
+	assert.True(t, shouldIgnorePath("pkg/process/handler_mock.go", []string{"**/*_mock.go"}))
+	assert.True(t, shouldIgnorePath("handler_mock.go", []string{"**/*_mock.go"}))
+	assert.False(t, shouldIgnorePath("pkg/process/handler.go", []string{"**/*_mock.go"}))
+
+	assert.True(t, shouldIgnorePath("pkg/process/testdata/file.go", []string{"**/testdata/**"}))
+	assert.True(t, shouldIgnorePath("testdata", []string{"**/testdata/**"}))
+	assert.False(t, shouldIgnorePath("pkg/process/testdata.go", []string{"**/testdata/**"}))
+
+	assert.True(t, shouldIgnorePath("docs/index.generated.js", []string{"docs/*.generated.js"}))
+	assert.False(t, shouldIgnorePath("docs/api/index.generated.js", []string{"docs/*.generated.js"}))
+	assert.False(t, shouldIgnorePath("docs/index.js", []string{"docs/*.generated.js"}))
+
+	assert.True(t, shouldIgnorePath("src/file1.js", []string{"src/file?.js"}))
+	assert.True(t, shouldIgnorePath("src/file1.js", []string{"src/file[0-9].js"}))
+	assert.False(t, shouldIgnorePath("src/fileA.js", []string{"src/file[0-9].js"}))
+}
+
+func TestShouldIgnoreAnchored(t *testing.T) {
+	assert.True(t, shouldIgnorePath("docs/index.js", []string{"/docs"}))
+	assert.False(t, shouldIgnorePath("src/docs/index.js", []string{"/docs"}))
+	assert.True(t, shouldIgnorePath("src/docs/index.js", []string{"docs"}))
+	assert.True(t, shouldIgnorePath("src/docs/index.js", []string{"/**/docs"}))
+}
+
+func TestShouldIgnoreNegated(t *testing.T) {
+	ignorePaths := []string{"vendor", "!vendor/ours", "vendor/ours/generated"}
+	assert.True(t, shouldIgnorePath("vendor/theirs/file.go", ignorePaths))
+	assert.False(t, shouldIgnorePath("vendor/ours/file.go", ignorePaths))
+	assert.True(t, shouldIgnorePath("vendor/ours/generated/file.go", ignorePaths))
+	assert.False(t, shouldIgnorePath("src/file.go", []string{"!src"}))
+
+	// Directories are only skipped when there are no negated patterns
+	assert.True(t, shouldSkipDir("vendor", []string{"vendor"}))
+	assert.False(t, shouldSkipDir("vendor", ignorePaths))
+}
+
+func TestParseIgnorePaths(t *testing.T) {
+	ignorePaths, err := ParseIgnorePaths("node_modules,,**/*_mock.go,!src/[ab].go")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"node_modules", "**/*_mock.go", "!src/[ab].go"}, ignorePaths)
+
+	ignorePaths, err = ParseIgnorePaths("")
+	assert.Nil(t, err)
+	assert.Empty(t, ignorePaths)
+
+	_, err = ParseIgnorePaths("src/[a-")
+	assert.NotNil(t, err)
+}
+
+func TestFiles_SkipsIgnoredDirs(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{
+		LicensePath: "license.txt",
+		Path:        "project",
+		Extensions:  []string{".go"},
+		IgnorePaths: []string{"/vendor", "**/*_mock.go"},
+	}
+	handler.pathsToWalk = []string{
+		"project/",
+		"project/vendor/",
+		"project/vendor/lib.go",
+		"project/src/vendor/",
+		"project/src/vendor/lib.go",
+		"project/src/handler_mock.go",
+	}
+	handler.On("WalkDir", options.Path, mock.Anything).Return(nil).Once()
+	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
+	handler.On("ReadFile", "project/.licenseignore").Return([]byte{}, fs.ErrNotExist).Once()
+	handler.On("ReadFile", "project/src/vendor/.licenseignore").Return([]byte{}, fs.ErrNotExist).Once()
+	handler.On("ReadFile", "project/src/vendor/lib.go").Return([]byte(testFileWithTargetLicense), nil).Once()
+
+	stats, err := Files(options, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"project/src/vendor/lib.go"}, stats.Files[LicenseOk])
+	handler.AssertExpectations(t)
+}
+
+func TestFiles_IgnoresWalkedPaths(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{
+		LicensePath: "license.txt",
+		Path:        "src",
+		Extensions:  []string{".go"},
+		IgnorePaths: []string{"src/generated", "src/mocks/*_mock.go"},
+	}
+	handler.pathsToWalk = []string{
+		"src/",
+		"src/generated/",
+		"src/generated/api.go",
+		"src/mocks/",
+		"src/mocks/handler_mock.go",
+		"src/main.go",
+	}
+	handler.On("WalkDir", options.Path, mock.Anything).Return(nil).Once()
+	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
+	handler.On("ReadFile", "src/.licenseignore").Return([]byte{}, fs.ErrNotExist).Once()
+	handler.On("ReadFile", "src/mocks/.licenseignore").Return([]byte{}, fs.ErrNotExist).Once()
+	handler.On("ReadFile", "src/main.go").Return([]byte(testFileWithTargetLicense), nil).Once()
+
+	// The patterns that include src-path are still matched against the walked paths
+	stats, err := Files(options, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"src/main.go"}, stats.Files[LicenseOk])
+	handler.AssertExpectations(t)
+}
@@ -0,0 +1,109 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Include is a pattern of the files to process besides the ones with the selected extensions
+// (e.g. Dockerfile or scripts/*). The pattern has the same syntax as the .gitignore files and
+// it is matched case insensitively against the paths relative to the root of the project.
+type Include struct {
+	Pattern string
+	// Language is the name of the language of the files that match the pattern. If empty, the
+	// language is looked up in the registry as usual.
+	Language string
+
+	pattern *ignorePattern
+}
+
+// ParseIncludes returns the include patterns of a comma separated list of pattern[=language]
+// items validating that the languages belong to the registry
+func ParseIncludes(value string, languages Languages) ([]*Include, error) {
+	var includes []*Include
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) == 0 {
+			continue
+		}
+		include := &Include{Pattern: item}
+		if i := strings.LastIndex(item, "="); i >= 0 {
+			include.Pattern, include.Language = item[:i], item[i+1:]
+			if languages.byName(include.Language) == nil {
+				return nil, fmt.Errorf("unknown language %q in include pattern %q", include.Language, item)
+			}
+		}
+		include.pattern = parseIgnorePattern(strings.ToLower(include.Pattern), "", "")
+		if include.pattern == nil || include.pattern.negated {
+			return nil, fmt.Errorf("invalid include pattern %q", item)
+		}
+		for _, segment := range include.pattern.segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid include pattern %q: %w", item, err)
+			}
+		}
+		includes = append(includes, include)
+	}
+	return includes, nil
+}
+
+// include returns the first include pattern that matches the file (or nil if there is none)
+func (o *Options) include(path string) *Include {
+	rel := strings.ToLower(relativePath(o.Path, path))
+	for _, include := range o.Includes {
+		if include.pattern != nil && include.pattern.matches(rel, false) {
+			return include
+		}
+	}
+	return nil
+}
+
+// shebangLength is the number of bytes read from the extensionless files to find their shebang,
+// which is the maximum length of the shebang line on Linux (plus a byte order mark)
+const shebangLength = 256 + len(byteOrderMark)
+
+// selectsInterpreter returns true if the interpreter of the shebang of the content belongs to a
+// language with any of the selected extensions (e.g. #!/bin/bash when .sh files are selected)
+func (o *Options) selectsInterpreter(content string) bool {
+	language := o.languages().FindInterpreter(content)
+	if language == nil {
+		return false
+	}
+	for _, extension := range language.Extensions {
+		for _, ext := range o.Extensions {
+			if strings.EqualFold(extension, ext) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// shouldIgnoreFile returns true if the file neither has one of the selected extensions nor matches
+// any of the include patterns
+func (o *Options) shouldIgnoreFile(path string) bool {
+	return shouldIgnoreExtension(path, o.Extensions) && o.include(path) == nil
+}
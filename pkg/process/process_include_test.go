@@ -0,0 +1,134 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseIncludes(t *testing.T) {
+	includes, err := ParseIncludes("Dockerfile*, Makefile,,bin/*=shell", DefaultLanguages())
+	assert.Nil(t, err)
+	assert.Len(t, includes, 3)
+	assert.Equal(t, "Dockerfile*", includes[0].Pattern)
+	assert.Empty(t, includes[0].Language)
+	assert.Equal(t, "bin/*", includes[2].Pattern)
+	assert.Equal(t, "shell", includes[2].Language)
+
+	includes, err = ParseIncludes("", DefaultLanguages())
+	assert.Nil(t, err)
+	assert.Empty(t, includes)
+
+	_, err = ParseIncludes("bin/*=unknown", DefaultLanguages())
+	assert.NotNil(t, err)
+	_, err = ParseIncludes("!Makefile", DefaultLanguages())
+	assert.NotNil(t, err)
+	_, err = ParseIncludes("bin/[a-", DefaultLanguages())
+	assert.NotNil(t, err)
+}
+
+func TestShouldIgnoreFile_Includes(t *testing.T) {
+	includes, _ := ParseIncludes("Dockerfile*,Jenkinsfile,/bin/*=shell,**/BUILD.bazel", DefaultLanguages())
+	options := &Options{Path: "project", Extensions: []string{".go"}, Includes: includes}
+
+	assert.False(t, options.shouldIgnoreFile("project/main.go"))
+	assert.False(t, options.shouldIgnoreFile("project/deploy/Dockerfile"))
+	assert.False(t, options.shouldIgnoreFile("project/deploy/dockerfile.dev"))
+	assert.False(t, options.shouldIgnoreFile("project/Jenkinsfile"))
+	assert.False(t, options.shouldIgnoreFile("project/bin/release"))
+	assert.False(t, options.shouldIgnoreFile("project/third_party/lib/BUILD.bazel"))
+	assert.True(t, options.shouldIgnoreFile("project/src/bin/release"))
+	assert.True(t, options.shouldIgnoreFile("project/bin/tools/release"))
+	assert.True(t, options.shouldIgnoreFile("project/README"))
+}
+
+func TestIncludeLanguage(t *testing.T) {
+	includes, _ := ParseIncludes("bin/*=shell,Dockerfile*", DefaultLanguages())
+	options := &Options{Includes: includes}
+
+	assert.Equal(t, "shell", options.language("bin/release").Name)
+	assert.Equal(t, "dockerfile", options.language("Dockerfile.prod").Name)
+	assert.Equal(t, "go", options.language("main.go").Name)
+	assert.Equal(t, "default", options.language("README").Name)
+}
+
+func TestFile_Include(t *testing.T) {
+	fileName := "bin/release"
+	handler := new(fileHandlerStub)
+	includes, _ := ParseIncludes("bin/*=shell", DefaultLanguages())
+	options := &Options{Add: true, Includes: includes}
+	content := "#!/bin/sh\necho release\n"
+
+	handler.On("WriteFile", fileName, []byte("#!/bin/sh\n\n# Copyright 2024 The Author\n\necho release\n")).Return(nil).Once()
+	assert.Equal(t, LicenseAdded, File(fileName, content, "Copyright 2024 The Author", options, handler))
+	handler.AssertExpectations(t)
+}
+
+func TestSelectsInterpreter(t *testing.T) {
+	options := &Options{Extensions: []string{".sh", ".PY"}}
+	assert.True(t, options.selectsInterpreter("#!/bin/bash\necho\n"))
+	assert.True(t, options.selectsInterpreter("#!/usr/bin/env python3\nprint()\n"))
+	assert.False(t, options.selectsInterpreter("#!/usr/bin/env ruby\nputs\n"))
+	assert.False(t, options.selectsInterpreter("echo\n"))
+}
+
+func TestFiles_Shebang(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{
+		LicensePath: "license.txt",
+		Path:        "project",
+		Extensions:  []string{".sh"},
+		Add:         true,
+	}
+	handler.pathsToWalk = []string{
+		"project/.git/",
+		"project/.git/hooks/pre-commit",
+		"project/bin/release",
+		"project/bin/tool",
+		"project/bin/locked",
+		"project/README",
+		"project/notes.txt",
+	}
+	handler.On("WalkDir", options.Path, mock.Anything).Return(nil).Once()
+	handler.On("ReadFile", "license.txt").Return([]byte("Copyright 2024 The Author"), nil).Once()
+	handler.On("ReadPrefix", "project/bin/release", shebangLength).Return([]byte("#!/bin/bash\necho release\n"), nil).Once()
+	handler.On("ReadPrefix", "project/bin/tool", shebangLength).Return([]byte("#!/usr/bin/env python3\nprint()\n"), nil).Once()
+	handler.On("ReadPrefix", "project/bin/locked", shebangLength).Return([]byte{}, fs.ErrPermission).Once()
+	handler.On("ReadPrefix", "project/README", shebangLength).Return([]byte("Read me\n"), nil).Once()
+	handler.On("ReadFile", "project/bin/release").Return([]byte("#!/bin/bash\necho release\n"), nil).Once()
+	handler.On("WriteFile", "project/bin/release", []byte("#!/bin/bash\n\n# Copyright 2024 The Author\n\necho release\n")).Return(nil).Once()
+
+	// Only the first line of the extensionless files is read to select them, the files that cannot
+	// be read are reported and the .git directory is never walked
+	stats, err := Files(options, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"project/bin/release"}, stats.Files[LicenseAdded])
+	assert.Equal(t, []string{"project/bin/locked"}, stats.Files[OperationError])
+	assert.Equal(t, 2, stats.Count(SkippedAdd, LicenseAdded, LicenseOk, OperationError))
+	handler.AssertExpectations(t)
+}
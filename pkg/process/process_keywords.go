@@ -0,0 +1,60 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import "strings"
+
+// DefaultKeywords are the words that make a comment a license header when no keywords are provided in the options
+var DefaultKeywords = []string{"copyright", "license"}
+
+// keywords returns the words that make a comment a license header
+func (o *Options) keywords() []string {
+	if o.Keywords == nil {
+		return DefaultKeywords
+	}
+	return o.Keywords
+}
+
+// isLicenseHeader returns true if the comment is a license header, which means that it does not match any
+// of the options.NotHeaderRules and that it contains any of the keywords (case insensitive) or matches any
+// of the options.HeaderRules
+func (o *Options) isLicenseHeader(comment string) bool {
+	for _, rule := range o.NotHeaderRules {
+		if rule.MatchString(comment) {
+			return false
+		}
+	}
+	lower := strings.ToLower(comment)
+	for _, keyword := range o.keywords() {
+		if len(keyword) > 0 && strings.Contains(lower, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	for _, rule := range o.HeaderRules {
+		if rule.MatchString(comment) {
+			return true
+		}
+	}
+	return false
+}
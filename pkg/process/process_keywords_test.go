@@ -0,0 +1,99 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLicenseHeader(t *testing.T) {
+	// Default keywords
+	options := &Options{}
+	assert.True(t, options.isLicenseHeader("// Copyright 2020 The Author"))
+	assert.True(t, options.isLicenseHeader("/* Licensed under the MIT LICENSE */"))
+	assert.False(t, options.isLicenseHeader("// Package main does things"))
+
+	// Custom keywords replace the default ones
+	options = &Options{Keywords: []string{"Lizenz", "Urheberrecht"}}
+	assert.True(t, options.isLicenseHeader("// Urheberrecht 2020 Der Autor"))
+	assert.True(t, options.isLicenseHeader("// Veröffentlicht unter der MIT-LIZENZ"))
+	assert.False(t, options.isLicenseHeader("// Copyright 2020 The Author"))
+
+	// Positive rules
+	options = &Options{HeaderRules: []*regexp.Regexp{regexp.MustCompile(`(?i)all rights reserved`)}}
+	assert.True(t, options.isLicenseHeader("// (c) 2020 Acme. All Rights Reserved."))
+	assert.True(t, options.isLicenseHeader("// Copyright 2020 The Author"))
+	assert.False(t, options.isLicenseHeader("// (c) 2020 Acme"))
+
+	// Negative rules take precedence over keywords and positive rules
+	options = &Options{
+		HeaderRules:    []*regexp.Regexp{regexp.MustCompile(`(?i)all rights reserved`)},
+		NotHeaderRules: []*regexp.Regexp{regexp.MustCompile(`^// Package `)},
+	}
+	assert.False(t, options.isLicenseHeader("// Package main reads the license file"))
+	assert.False(t, options.isLicenseHeader("// Package main. All rights reserved."))
+	assert.True(t, options.isLicenseHeader("// Copyright 2020 The Author"))
+}
+
+func TestFile_DetectionRules(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	license := "// Copyright 2020 The Author"
+	content := "// Prints the license of the project\n\npackage main\n"
+
+	// Comments that mention the license are headers by default
+	op := File(fileName, content, license, &Options{Add: true}, handler)
+	assert.Equal(t, SkippedReplace, op)
+
+	// Unless they are line comments attached to the code, such as the doc comment of a package
+	docContent := "// Package license parses the license files of a project.\npackage license\n"
+	handler.On("WriteFile", fileName, []byte(license+"\n\n"+docContent)).Return(nil).Once()
+	op = File(fileName, docContent, license, &Options{Add: true, Replace: true}, handler)
+	assert.Equal(t, LicenseAdded, op)
+	handler.AssertExpectations(t)
+
+	pyContent := "# Install the license tooling\nimport os\n"
+	handler.On("WriteFile", "setup.py", []byte("# Copyright 2020 The Author\n\n"+pyContent)).Return(nil).Once()
+	op = File("setup.py", pyContent, "Copyright 2020 The Author", &Options{Add: true, Replace: true}, handler)
+	assert.Equal(t, LicenseAdded, op)
+	handler.AssertExpectations(t)
+
+	// Comments excluded by a negative rule are not headers
+	options := &Options{Add: true, NotHeaderRules: []*regexp.Regexp{regexp.MustCompile(`^// Prints `)}}
+	handler.On("WriteFile", fileName, []byte(license+"\n\n"+content)).Return(nil).Once()
+	op = File(fileName, content, license, options, handler)
+	assert.Equal(t, LicenseAdded, op)
+	handler.AssertExpectations(t)
+
+	// Headers found by custom keywords are replaced
+	content = "// Urheberrecht 2019 Der Autor\n\npackage main\n"
+	options = &Options{Replace: true, Keywords: []string{"urheberrecht"}}
+	handler.On("WriteFile", fileName, []byte(license+"\n\npackage main\n")).Return(nil).Once()
+	op = File(fileName, content, license, options, handler)
+	assert.Equal(t, LicenseReplaced, op)
+	handler.AssertExpectations(t)
+}
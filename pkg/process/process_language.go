@@ -0,0 +1,363 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type (
+	// Language defines the comment syntax of a group of files
+	Language struct {
+		// Name identifies the language. Custom languages with the name of a built-in one replace it.
+		Name string `json:"name"`
+		// Extensions of the files written in the language (including the dot)
+		Extensions []string `json:"extensions"`
+		// Filenames of the files written in the language regardless of their extension (e.g. Makefile).
+		// They are case sensitive and can contain the wildcards of path.Match (e.g. Dockerfile.* or
+		// [Mm]akefile).
+		Filenames []string `json:"filenames"`
+		// BlockStart is the delimiter that opens a block comment (e.g. /*)
+		BlockStart string `json:"block_start"`
+		// BlockEnd is the delimiter that closes a block comment (e.g. */)
+		BlockEnd string `json:"block_end"`
+		// BlockPrefix is the prefix of each line inside a block comment when rendering a license (e.g. " * ")
+		BlockPrefix string `json:"block_prefix"`
+		// LinePrefix is the prefix of a line comment (e.g. //)
+		LinePrefix string `json:"line_prefix"`
+		// Preamble are the regular expressions of the lines that must stay before the
+		// license header (e.g. shebang or build tags)
+		Preamble []string `json:"preamble"`
+		// EncodingLine is true if an encoding declaration (e.g. # -*- coding: utf-8 -*-) in one of
+		// the first two lines is part of the preamble, as defined by PEP 263
+		EncodingLine bool `json:"encoding_line"`
+		// Interpreters are the names of the programs of the shebang of the extensionless files
+		// written in the language, without version numbers (e.g. python for #!/usr/bin/env python3)
+		Interpreters []string `json:"interpreters"`
+
+		headerRegex   *regexp.Regexp
+		preambleRegex []*regexp.Regexp
+	}
+
+	// Languages is a registry of languages that can be looked up by file path
+	Languages []*Language
+)
+
+// encodingRegex matches the encoding declarations of PEP 263, which are only valid in the first two lines
+var encodingRegex = regexp.MustCompile(`^[ \t\f]*#.*?coding[:=][ \t]*[-_.a-zA-Z0-9]+`)
+
+// byteOrderMark is the UTF-8 byte order mark, which always stays at the beginning of the content
+const byteOrderMark = "\ufeff"
+
+// defaultLanguage is used for the files that do not match any language of the registry
+var defaultLanguage = mustCompile(&Language{Name: "default", BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * "})
+
+// defaultLanguages is used when no registry is provided in the options
+var defaultLanguages = DefaultLanguages()
+
+// DefaultLanguages returns the built-in languages registry
+func DefaultLanguages() Languages {
+	shebang := `^#!`
+	languages := Languages{
+		{Name: "c", Extensions: []string{".c", ".h", ".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx", ".m", ".mm"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//"},
+		{Name: "csharp", Extensions: []string{".cs"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//"},
+		{Name: "css", Extensions: []string{".css"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * "},
+		{Name: "dart", Extensions: []string{".dart"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//"},
+		{Name: "go", Extensions: []string{".go"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//", Preamble: []string{`^//go:build `, `^// \+build `}},
+		{Name: "groovy", Extensions: []string{".groovy", ".gradle"}, Filenames: []string{"Jenkinsfile"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//", Preamble: []string{shebang}, Interpreters: []string{"groovy"}},
+		{Name: "java", Extensions: []string{".java"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//"},
+		{Name: "javascript", Extensions: []string{".js", ".jsx", ".mjs", ".cjs"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//", Preamble: []string{shebang}, Interpreters: []string{"node", "nodejs"}},
+		{Name: "kotlin", Extensions: []string{".kt", ".kts"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//", Preamble: []string{shebang}, Interpreters: []string{"kotlin"}},
+		{Name: "less", Extensions: []string{".less", ".scss", ".sass"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//"},
+		{Name: "php", Extensions: []string{".php"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//", Preamble: []string{shebang, `^<\?php`}, Interpreters: []string{"php"}},
+		{Name: "protobuf", Extensions: []string{".proto"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//"},
+		{Name: "rust", Extensions: []string{".rs"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//", Preamble: []string{`^#!\[`}},
+		{Name: "scala", Extensions: []string{".scala", ".sc"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//"},
+		{Name: "swift", Extensions: []string{".swift"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//", Preamble: []string{shebang}, Interpreters: []string{"swift"}},
+		{Name: "typescript", Extensions: []string{".ts", ".tsx", ".mts", ".cts"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "//", Preamble: []string{shebang}, Interpreters: []string{"ts-node"}},
+		{Name: "sql", Extensions: []string{".sql"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "--"},
+		{Name: "terraform", Extensions: []string{".tf", ".tfvars", ".hcl"}, BlockStart: "/*", BlockEnd: "*/", BlockPrefix: " * ", LinePrefix: "#", Preamble: []string{shebang}},
+		{Name: "python", Extensions: []string{".py", ".pyw", ".pyi"}, LinePrefix: "#", Preamble: []string{shebang}, EncodingLine: true, Interpreters: []string{"python", "pypy"}},
+		{Name: "shell", Extensions: []string{".sh", ".bash", ".zsh", ".ksh"}, LinePrefix: "#", Preamble: []string{shebang}, Interpreters: []string{"sh", "bash", "zsh", "ksh", "dash", "ash"}},
+		{Name: "ruby", Extensions: []string{".rb", ".rake", ".gemspec"}, Filenames: []string{"Rakefile", "Gemfile"}, LinePrefix: "#", Preamble: []string{shebang}, EncodingLine: true, Interpreters: []string{"ruby"}},
+		{Name: "perl", Extensions: []string{".pl", ".pm"}, LinePrefix: "#", Preamble: []string{shebang}, Interpreters: []string{"perl"}},
+		{Name: "r", Extensions: []string{".r"}, LinePrefix: "#", Preamble: []string{shebang}, Interpreters: []string{"rscript"}},
+		{Name: "elixir", Extensions: []string{".ex", ".exs"}, LinePrefix: "#", Preamble: []string{shebang}, Interpreters: []string{"elixir"}},
+		{Name: "powershell", Extensions: []string{".ps1", ".psm1"}, BlockStart: "<#", BlockEnd: "#>", LinePrefix: "#", Preamble: []string{shebang}, Interpreters: []string{"pwsh"}},
+		{Name: "yaml", Extensions: []string{".yml", ".yaml"}, LinePrefix: "#", Preamble: []string{shebang}},
+		{Name: "toml", Extensions: []string{".toml"}, LinePrefix: "#", Preamble: []string{shebang}},
+		{Name: "make", Extensions: []string{".mk"}, Filenames: []string{"Makefile", "makefile", "GNUmakefile"}, LinePrefix: "#", Preamble: []string{shebang}, Interpreters: []string{"make"}},
+		{Name: "starlark", Extensions: []string{".bzl", ".bazel", ".star"}, Filenames: []string{"BUILD", "WORKSPACE"}, LinePrefix: "#", Preamble: []string{shebang}},
+		{Name: "cmake", Extensions: []string{".cmake"}, Filenames: []string{"CMakeLists.txt"}, LinePrefix: "#", Preamble: []string{shebang}},
+		{Name: "dockerfile", Extensions: []string{".dockerfile"}, Filenames: []string{"Dockerfile", "Dockerfile.*", "Containerfile"}, LinePrefix: "#", Preamble: []string{shebang, `^#\s*(syntax|escape)=`}},
+		{Name: "html", Extensions: []string{".html", ".htm", ".vue"}, BlockStart: "<!--", BlockEnd: "-->", BlockPrefix: "  ", Preamble: []string{`(?i)^<!doctype`}},
+		{Name: "markdown", Extensions: []string{".md", ".markdown"}, BlockStart: "<!--", BlockEnd: "-->", BlockPrefix: "  "},
+		{Name: "xml", Extensions: []string{".xml", ".xsd", ".xsl", ".svg", ".plist"}, BlockStart: "<!--", BlockEnd: "-->", BlockPrefix: "  ", Preamble: []string{`^<\?xml`}},
+		{Name: "lua", Extensions: []string{".lua"}, BlockStart: "--[[", BlockEnd: "]]", LinePrefix: "--", Preamble: []string{shebang}, Interpreters: []string{"lua", "luajit"}},
+		{Name: "haskell", Extensions: []string{".hs"}, BlockStart: "{-", BlockEnd: "-}", LinePrefix: "--", Preamble: []string{shebang}, Interpreters: []string{"runhaskell"}},
+		{Name: "ocaml", Extensions: []string{".ml", ".mli", ".fs", ".fsi"}, BlockStart: "(*", BlockEnd: "*)"},
+		{Name: "erlang", Extensions: []string{".erl", ".hrl"}, LinePrefix: "%"},
+		{Name: "tex", Extensions: []string{".tex", ".sty", ".cls"}, LinePrefix: "%"},
+		{Name: "lisp", Extensions: []string{".lisp", ".el", ".clj", ".cljs", ".scm"}, LinePrefix: ";;"},
+		{Name: "ini", Extensions: []string{".ini"}, LinePrefix: ";"},
+	}
+	for _, language := range languages {
+		mustCompile(language)
+	}
+	return languages
+}
+
+// ParseLanguages parses a JSON array of languages
+func ParseLanguages(data []byte) (Languages, error) {
+	var languages Languages
+	if err := json.Unmarshal(data, &languages); err != nil {
+		return nil, err
+	}
+	for _, language := range languages {
+		if err := language.Compile(); err != nil {
+			return nil, err
+		}
+	}
+	return languages, nil
+}
+
+// Merge returns a registry with the languages of both registries. The languages of
+// other replace the ones of the current registry with the same name.
+func (l Languages) Merge(other Languages) Languages {
+	merged := make(Languages, 0, len(l)+len(other))
+	for _, language := range l {
+		if other.byName(language.Name) == nil {
+			merged = append(merged, language)
+		}
+	}
+	return append(merged, other...)
+}
+
+// Find returns the language of the file or nil if there is none. Filenames take precedence over
+// extensions and the longest matching extension wins (e.g. .d.ts over .ts). Filenames are compared
+// case sensitively (e.g. BUILD does not match a build script) while extensions are compared case
+// insensitively.
+func (l Languages) Find(path string) *Language {
+	base := filepath.Base(path)
+	for _, language := range l {
+		for _, filename := range language.Filenames {
+			if ok, _ := filepath.Match(filename, base); ok {
+				return language
+			}
+		}
+	}
+	name := strings.ToLower(base)
+	var found *Language
+	longest := 0
+	for _, language := range l {
+		for _, extension := range language.Extensions {
+			if len(extension) > longest && strings.HasSuffix(name, strings.ToLower(extension)) {
+				found, longest = language, len(extension)
+			}
+		}
+	}
+	return found
+}
+
+// FindInterpreter returns the language of the interpreter of the shebang of the content (e.g.
+// #!/bin/bash or #!/usr/bin/env python3) or nil if there is none
+func (l Languages) FindInterpreter(content string) *Language {
+	name := interpreter(content)
+	if len(name) == 0 {
+		return nil
+	}
+	for _, language := range l {
+		for _, interpreter := range language.Interpreters {
+			if strings.EqualFold(interpreter, name) {
+				return language
+			}
+		}
+	}
+	return nil
+}
+
+// interpreter returns the name of the program of the shebang of the content without its version
+// (e.g. python for #!/usr/bin/env python3.11) or an empty string if there is no shebang
+func interpreter(content string) string {
+	content = strings.TrimPrefix(content, byteOrderMark)
+	if !strings.HasPrefix(content, "#!") {
+		return ""
+	}
+	line := content[2:]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	program := filepath.Base(fields[0])
+	// env receives the program after its options and environment variables
+	if program == "env" {
+		program = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				program = filepath.Base(field)
+				break
+			}
+		}
+	}
+	return strings.TrimRight(program, "0123456789.")
+}
+
+func (l Languages) byName(name string) *Language {
+	for _, language := range l {
+		if language.Name == name {
+			return language
+		}
+	}
+	return nil
+}
+
+// Compile validates the comment syntax of the language and compiles its regular expressions.
+// It must be called before using a language that has not been returned by DefaultLanguages or
+// ParseLanguages.
+func (l *Language) Compile() error {
+	if len(l.Name) == 0 {
+		return errors.New("language without name")
+	}
+	if (len(l.BlockStart) == 0) != (len(l.BlockEnd) == 0) {
+		return fmt.Errorf("language %s: block_start and block_end must be provided together", l.Name)
+	}
+	if len(l.BlockStart) == 0 && len(l.LinePrefix) == 0 {
+		return fmt.Errorf("language %s: either block comments or line comments must be provided", l.Name)
+	}
+
+	// The header is either a block comment or a run of consecutive line comments, which
+	// ends at the first blank or non-comment line
+	var patterns []string
+	if len(l.BlockStart) > 0 {
+		patterns = append(patterns, `(?s:`+regexp.QuoteMeta(l.BlockStart)+`.*?`+regexp.QuoteMeta(l.BlockEnd)+`)`)
+	}
+	if len(l.LinePrefix) > 0 {
+		prefix := regexp.QuoteMeta(l.LinePrefix)
+		patterns = append(patterns, `(?m:^[ \t]*`+prefix+`.*(?:\n[ \t]*`+prefix+`.*)*)`)
+	}
+	l.headerRegex = regexp.MustCompile(strings.Join(patterns, "|"))
+
+	l.preambleRegex = nil
+	for _, preamble := range l.Preamble {
+		re, err := regexp.Compile(preamble)
+		if err != nil {
+			return fmt.Errorf("language %s: %w", l.Name, err)
+		}
+		l.preambleRegex = append(l.preambleRegex, re)
+	}
+	return nil
+}
+
+// withHeaderRegex returns a copy of the language that uses a custom regular expression
+// to find the header
+func (l *Language) withHeaderRegex(re *regexp.Regexp) *Language {
+	language := *l
+	language.headerRegex = re
+	return &language
+}
+
+// preambleEnd returns the position of the content right after the byte order mark and
+// the preamble lines (if any)
+func (l *Language) preambleEnd(content string) int {
+	end := 0
+	if strings.HasPrefix(content, byteOrderMark) {
+		end = len(byteOrderMark)
+	}
+	for offset, number := end, 0; offset < len(content); number++ {
+		next := len(content)
+		if i := strings.IndexByte(content[offset:], '\n'); i >= 0 {
+			next = offset + i + 1
+		}
+		line := strings.TrimRight(content[offset:next], "\r\n")
+		if l.isPreamble(line) || (l.EncodingLine && number < 2 && encodingRegex.MatchString(line)) {
+			end = next
+		} else if len(strings.TrimSpace(line)) > 0 {
+			break
+		}
+		offset = next
+	}
+	return end
+}
+
+func (l *Language) isPreamble(line string) bool {
+	for _, re := range l.preambleRegex {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustCompile(l *Language) *Language {
+	if err := l.Compile(); err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// languages returns the registry of languages to be used
+func (o *Options) languages() Languages {
+	if o.Languages == nil {
+		return defaultLanguages
+	}
+	return o.Languages
+}
+
+// language returns the language used to process the file, which is the one of the include
+// pattern that matches it (if any) or the one found in the registry. If a custom header regex
+// has been provided, it takes precedence over the one of the language.
+func (o *Options) language(path string) *Language {
+	return o.fileLanguage(path, "")
+}
+
+// fileLanguage returns the language used to process the file like language does, but the
+// language of the extensionless files with a shebang is the one of its interpreter (if any),
+// which takes precedence over the registry (e.g. a bin/build script is not a BUILD file)
+func (o *Options) fileLanguage(path, content string) *Language {
+	var language *Language
+	if include := o.include(path); include != nil && len(include.Language) > 0 {
+		language = o.languages().byName(include.Language)
+	}
+	if language == nil && len(filepath.Ext(path)) == 0 {
+		language = o.languages().FindInterpreter(content)
+	}
+	if language == nil {
+		language = o.languages().Find(path)
+	}
+	if language == nil {
+		language = defaultLanguage
+	}
+	if o.HeaderRegex != nil {
+		language = language.withHeaderRegex(o.HeaderRegex)
+	}
+	return language
+}
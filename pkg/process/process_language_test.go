@@ -0,0 +1,160 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindLanguage(t *testing.T) {
+	languages := DefaultLanguages()
+	assert.Equal(t, "go", languages.Find("cmd/main.go").Name)
+	assert.Equal(t, "python", languages.Find("src/neural.py").Name)
+	assert.Equal(t, "make", languages.Find("build/Makefile").Name)
+	assert.Equal(t, "html", languages.Find("index.html").Name)
+	assert.Equal(t, "markdown", languages.Find("README.md").Name)
+	assert.Nil(t, languages.Find("README"))
+	assert.Nil(t, languages.Find("file.unknown"))
+}
+
+func TestFindLanguage_NamesAndCase(t *testing.T) {
+	languages := DefaultLanguages()
+	assert.Equal(t, "javascript", languages.Find("src/INDEX.JS").Name)
+	assert.Equal(t, "make", languages.Find("makefile").Name)
+	assert.Equal(t, "dockerfile", languages.Find("deploy/Dockerfile.dev").Name)
+	assert.Equal(t, "starlark", languages.Find("pkg/BUILD.bazel").Name)
+	assert.Equal(t, "starlark", languages.Find("BUILD").Name)
+
+	// The longest extension wins
+	languages = append(languages, mustCompile(&Language{Name: "declarations", Extensions: []string{".d.ts"}, LinePrefix: "//"}))
+	assert.Equal(t, "declarations", languages.Find("types/index.d.ts").Name)
+	assert.Equal(t, "typescript", languages.Find("src/index.ts").Name)
+}
+
+func TestParseLanguages(t *testing.T) {
+	data := []byte(`[
+		{"name": "python", "extensions": [".py"], "block_start": "\"\"\"", "block_end": "\"\"\""},
+		{"name": "nim", "extensions": [".nim"], "line_prefix": "#", "preamble": ["^#!"]}
+	]`)
+	custom, err := ParseLanguages(data)
+	assert.Nil(t, err)
+	assert.Len(t, custom, 2)
+
+	languages := DefaultLanguages().Merge(custom)
+	assert.Len(t, languages, len(DefaultLanguages())+1)
+	assert.Equal(t, `"""`, languages.Find("main.py").BlockStart)
+	assert.Equal(t, "nim", languages.Find("main.nim").Name)
+
+	_, err = ParseLanguages([]byte(`[{"name": "broken", "block_start": "/*"}]`))
+	assert.NotNil(t, err)
+
+	_, err = ParseLanguages([]byte(`[{"name": "empty"}]`))
+	assert.NotNil(t, err)
+
+	_, err = ParseLanguages([]byte(`[{"name": "bad_preamble", "line_prefix": "#", "preamble": ["("]}]`))
+	assert.NotNil(t, err)
+
+	_, err = ParseLanguages([]byte(`{}`))
+	assert.NotNil(t, err)
+}
+
+func TestOptionsLanguage(t *testing.T) {
+	options := &Options{}
+	assert.Equal(t, "python", options.language("main.py").Name)
+	assert.Equal(t, defaultLanguage, options.language("main.unknown"))
+
+	options.Languages = Languages{mustCompile(&Language{Name: "custom", Extensions: []string{".py"}, LinePrefix: ";"})}
+	assert.Equal(t, "custom", options.language("main.py").Name)
+
+	re := regexp.MustCompile(`"""(.|[\r\n])*"""`)
+	options.HeaderRegex = re
+	assert.Equal(t, re, options.language("main.py").headerRegex)
+}
+
+func TestExtractHeaderLineComments(t *testing.T) {
+	python := DefaultLanguages().Find("main.py")
+	content := "#!/usr/bin/env python\n\n# Copyright (c) 2020 The Author\n# Licensed under MIT\n\nprint('Hello')\n"
+	assert.Equal(t, "# Copyright (c) 2020 The Author\n# Licensed under MIT", extractHeader(python, content))
+	assert.True(t, containsLicenseHeader(python, content, &Options{}))
+	assert.False(t, containsLicenseHeader(python, "#!/usr/bin/env python\n\nprint('Hello')\n", &Options{}))
+
+	html := DefaultLanguages().Find("index.html")
+	content = "<!DOCTYPE html>\n<!-- Copyright (c) 2020 The Author -->\n<html></html>\n"
+	assert.Equal(t, "<!-- Copyright (c) 2020 The Author -->", extractHeader(html, content))
+}
+
+func TestFindInterpreter(t *testing.T) {
+	languages := DefaultLanguages()
+	assert.Equal(t, "shell", languages.FindInterpreter("#!/bin/bash\necho\n").Name)
+	assert.Equal(t, "shell", languages.FindInterpreter("#! /bin/sh -e\necho\n").Name)
+	assert.Equal(t, "python", languages.FindInterpreter("#!/usr/bin/env python3\nprint()\n").Name)
+	assert.Equal(t, "python", languages.FindInterpreter("\ufeff#!/usr/bin/python3.11\r\nprint()\n").Name)
+	assert.Equal(t, "javascript", languages.FindInterpreter("#!/usr/bin/env -S node --no-warnings\n").Name)
+	assert.Equal(t, "ruby", languages.FindInterpreter("#!/usr/bin/env RUBYOPT=-w ruby\n").Name)
+	assert.Equal(t, "r", languages.FindInterpreter("#!/usr/bin/env Rscript\n").Name)
+	assert.Nil(t, languages.FindInterpreter("#!/usr/bin/env unknown\n"))
+	assert.Nil(t, languages.FindInterpreter("#!\n"))
+	assert.Nil(t, languages.FindInterpreter("echo\n#!/bin/bash\n"))
+	assert.Nil(t, languages.FindInterpreter(""))
+}
+
+func TestFileLanguage(t *testing.T) {
+	options := &Options{}
+	assert.Equal(t, "shell", options.fileLanguage("bin/release", "#!/bin/bash\n").Name)
+	assert.Equal(t, defaultLanguage, options.fileLanguage("bin/release", "echo\n"))
+
+	// Only the extensionless files are sniffed
+	assert.Equal(t, defaultLanguage, options.fileLanguage("bin/release.unknown", "#!/bin/bash\n"))
+	assert.Equal(t, "make", options.fileLanguage("Makefile", "all:\n").Name)
+
+	// The shebang takes precedence over the registry, whose filenames are case sensitive
+	assert.Equal(t, "shell", options.fileLanguage("bin/build", "#!/bin/bash\n").Name)
+	assert.Equal(t, "shell", options.fileLanguage("bin/BUILD", "#!/bin/bash\n").Name)
+	assert.Equal(t, "make", options.fileLanguage("Makefile", "#!/usr/bin/make -f\n").Name)
+	assert.Equal(t, "starlark", options.fileLanguage("BUILD", "load(\"//:defs.bzl\", \"rule\")\n").Name)
+	assert.Equal(t, defaultLanguage, options.fileLanguage("bin/build", "echo\n"))
+}
+
+func TestFile_ShebangBeforeFilename(t *testing.T) {
+	fileName := "bin/build"
+	handler := new(fileHandlerStub)
+	options := &Options{Add: true}
+
+	// The license is added after the shebang of a script named like a language of the registry
+	handler.On("WriteFile", fileName, []byte("#!/bin/bash\n\n# Copyright 2024 The Author\n\necho build\n")).Return(nil).Once()
+	op := File(fileName, "#!/bin/bash\necho build\n", "Copyright 2024 The Author", options, handler)
+	assert.Equal(t, LicenseAdded, op)
+	handler.AssertExpectations(t)
+
+	// The shebang is kept in the preamble of every language with # comments
+	fileName = "bin/BUILD"
+	options.Includes, _ = ParseIncludes("bin/*=starlark", DefaultLanguages())
+	handler.On("WriteFile", fileName, []byte("#!/usr/bin/env custom\n\n# Copyright 2024 The Author\n\nbuild()\n")).Return(nil).Once()
+	op = File(fileName, "#!/usr/bin/env custom\nbuild()\n", "Copyright 2024 The Author", options, handler)
+	assert.Equal(t, LicenseAdded, op)
+	handler.AssertExpectations(t)
+}
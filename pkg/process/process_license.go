@@ -0,0 +1,109 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+// licenseSet is the target license and the other licenses accepted in the files
+type licenseSet struct {
+	// target is the license header added to (or replaced in) the files
+	target string
+	// allowed are the license headers accepted besides the target one
+	allowed []allowedLicense
+}
+
+// allowedLicense is a license header accepted besides the target one
+type allowedLicense struct {
+	name   string
+	header string
+}
+
+// readLicenses reads (and validates) the target license header and the allowed ones
+func readLicenses(options *Options, h fileHandler) (*licenseSet, error) {
+	licenses := new(licenseSet)
+
+	if len(options.SPDX) > 0 {
+		if _, err := ParseSPDX(options.SPDX); err != nil {
+			return nil, err
+		}
+	} else if !options.RemoveAny {
+		data, err := h.ReadFile(options.LicensePath)
+		if err != nil {
+			return nil, err
+		}
+		licenses.target = string(data)
+		if _, err := newLicenseTemplate(options.LicensePath, licenses.target, options); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range options.AllowedLicensePaths {
+		data, err := h.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := newLicenseTemplate(path, string(data), options); err != nil {
+			return nil, err
+		}
+		licenses.allowed = append(licenses.allowed, allowedLicense{name: path, header: string(data)})
+	}
+
+	for _, expression := range options.AllowedSPDX {
+		if _, err := ParseSPDX(expression); err != nil {
+			return nil, err
+		}
+	}
+
+	return licenses, nil
+}
+
+// matchAllowed returns the name of the allowed license header or SPDX expression found in
+// the content (if any)
+func (l *licenseSet) matchAllowed(path, content string, lang *Language, options *Options) (string, bool) {
+	for _, allowed := range l.allowed {
+		tmpl, err := newLicenseTemplate(path, options.renderLicense(allowed.header, lang), options)
+		if err != nil {
+			continue
+		}
+		if loc := tmpl.pattern.FindStringIndex(content); loc != nil && isLeading(lang, content, loc[0]) {
+			return allowed.name, true
+		}
+	}
+
+	if len(options.AllowedSPDX) == 0 {
+		return "", false
+	}
+	start, end, ok := findSPDX(lang, content)
+	if !ok {
+		return "", false
+	}
+	found, err := ParseSPDX(content[start:end])
+	if err != nil {
+		return "", false
+	}
+	for _, expression := range options.AllowedSPDX {
+		if allowed, err := ParseSPDX(expression); err == nil && allowed.Equal(found) {
+			return expression, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,136 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testBSDLicense = "/* Copyright (c) 2015 Contributor. Use of this source code is governed by a BSD-style license. */"
+
+func TestReadLicenses(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{
+		LicensePath:         "license.txt",
+		AllowedLicensePaths: []string{"bsd.txt"},
+		AllowedSPDX:         []string{"Apache-2.0"},
+	}
+	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
+	handler.On("ReadFile", "bsd.txt").Return([]byte(testBSDLicense), nil).Once()
+
+	licenses, err := readLicenses(options, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, testTargetLicenseHeader, licenses.target)
+	assert.Equal(t, []allowedLicense{{name: "bsd.txt", header: testBSDLicense}}, licenses.allowed)
+	handler.AssertExpectations(t)
+
+	// Return error if an allowed license cannot be read
+	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
+	handler.On("ReadFile", "bsd.txt").Return([]byte{}, errors.New("error")).Once()
+	_, err = readLicenses(options, handler)
+	assert.NotNil(t, err)
+	handler.AssertExpectations(t)
+
+	// Return error if an allowed SPDX expression is not valid
+	options.AllowedLicensePaths = nil
+	options.AllowedSPDX = []string{"Apache-2.0 OR"}
+	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
+	_, err = readLicenses(options, handler)
+	assert.NotNil(t, err)
+	handler.AssertExpectations(t)
+}
+
+func TestMatchAllowed(t *testing.T) {
+	options := &Options{AllowedSPDX: []string{"Apache-2.0", "BSD-3-Clause OR MIT"}}
+	licenses := &licenseSet{allowed: []allowedLicense{{name: "bsd.txt", header: testBSDLicense}}}
+	lang := options.language("main.go")
+
+	name, ok := licenses.matchAllowed("main.go", testBSDLicense+"\n\npackage main\n", lang, options)
+	assert.True(t, ok)
+	assert.Equal(t, "bsd.txt", name)
+
+	name, ok = licenses.matchAllowed("main.go", "// SPDX-License-Identifier: bsd-3-clause or mit\n\npackage main\n", lang, options)
+	assert.True(t, ok)
+	assert.Equal(t, "BSD-3-Clause OR MIT", name)
+
+	_, ok = licenses.matchAllowed("main.go", "// SPDX-License-Identifier: MIT\n\npackage main\n", lang, options)
+	assert.False(t, ok)
+
+	_, ok = licenses.matchAllowed("main.go", testFileWithDifferentLicense, lang, options)
+	assert.False(t, ok)
+}
+
+func TestFile_AllowedLicense(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	options := &Options{Add: true, Replace: true}
+	licenses := &licenseSet{target: testTargetLicenseHeader, allowed: []allowedLicense{{name: "bsd.txt", header: testBSDLicense}}}
+
+	// Files with an allowed license are never replaced
+	op := fileOperation(fileName, testBSDLicense+"\n\npackage main\n", licenses, options, handler)
+	assert.Equal(t, &Operation{Action: LicenseOk, Path: fileName, License: "bsd.txt"}, op)
+
+	// The target license is the one reported when both are present
+	op = fileOperation(fileName, testFileWithTargetLicense+testBSDLicense, licenses, options, handler)
+	assert.Equal(t, LicenseOk, op.Action)
+	assert.Empty(t, op.License)
+
+	// Only the target license is added
+	handler.On("WriteFile", fileName, []byte(testFileWithTargetLicense)).Return(nil).Once()
+	op = fileOperation(fileName, testFileWithoutLicense, licenses, options, handler)
+	assert.Equal(t, &Operation{Action: LicenseAdded, Path: fileName}, op)
+	handler.AssertExpectations(t)
+
+	// Allowed SPDX expressions are accepted in SPDX mode too
+	options.SPDX = "MIT"
+	options.AllowedSPDX = []string{"Apache-2.0"}
+	op = fileOperation(fileName, "// SPDX-License-Identifier: Apache-2.0\n\npackage main\n", licenses, options, handler)
+	assert.Equal(t, &Operation{Action: LicenseOk, Path: fileName, License: "Apache-2.0"}, op)
+}
+
+func TestFiles_AllowedLicenses(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{
+		Replace:             true,
+		LicensePath:         "license.txt",
+		AllowedLicensePaths: []string{"bsd.txt"},
+		Extensions:          []string{".go"},
+	}
+
+	handler.pathsToWalk = []string{"vendored.go"}
+	handler.On("WalkDir", options.Path, mock.Anything).Return(nil).Once()
+	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
+	handler.On("ReadFile", "bsd.txt").Return([]byte(testBSDLicense), nil).Once()
+	handler.On("ReadFile", "vendored.go").Return([]byte(testBSDLicense+"\n\npackage main\n"), nil).Once()
+
+	stats, err := Files(options, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"vendored.go"}, stats.Files[LicenseOk])
+	assert.Equal(t, map[string]string{"vendored.go": "bsd.txt"}, stats.Licenses)
+	handler.AssertExpectations(t)
+}
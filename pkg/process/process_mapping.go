@@ -0,0 +1,156 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NotMappedReason is the reason of the headers that are not replaced as they do not match any mapping
+// and only the mapped headers are replaced (see Options.MappingsOnly)
+const NotMappedReason = "not mapped"
+
+// Mapping replaces a specific license header by a new one
+type Mapping struct {
+	// Name identifies the mapping in the reports
+	Name string `json:"name"`
+	// Old is the text of the license header to be replaced (it may contain variables)
+	Old string `json:"old"`
+	// OldRegex is a regular expression that matches the license header to be replaced (instead of Old)
+	OldRegex string `json:"old_regex"`
+	// New is the text of the license header that replaces the old one. The target license is used if empty.
+	New string `json:"new"`
+
+	oldRegex *regexp.Regexp
+}
+
+// ParseMappings parses a JSON array of mappings
+func ParseMappings(data []byte) ([]*Mapping, error) {
+	var mappings []*Mapping
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		return nil, err
+	}
+	for _, mapping := range mappings {
+		if err := mapping.Compile(); err != nil {
+			return nil, err
+		}
+	}
+	return mappings, nil
+}
+
+// Compile validates the mapping and compiles its regular expression. It must be called before
+// using a mapping that has not been returned by ParseMappings.
+func (m *Mapping) Compile() error {
+	if len(m.Name) == 0 {
+		return errors.New("mapping without name")
+	}
+	if (len(strings.TrimSpace(m.Old)) == 0) == (len(m.OldRegex) == 0) {
+		return fmt.Errorf("mapping %s: either old or old_regex must be provided", m.Name)
+	}
+	m.oldRegex = nil
+	if len(m.OldRegex) > 0 {
+		re, err := regexp.Compile(m.OldRegex)
+		if err != nil {
+			return fmt.Errorf("mapping %s: %w", m.Name, err)
+		}
+		m.oldRegex = re
+	}
+	return nil
+}
+
+// matches returns true if the header of the file is the old one of the mapping
+func (m *Mapping) matches(path string, header *HeaderMatch, lang *Language, options *Options) bool {
+	if m.oldRegex != nil {
+		return m.oldRegex.MatchString(header.Text)
+	}
+	tmpl, err := newLicenseTemplate(path, options.renderLicense(m.Old, lang), options)
+	return err == nil && tmpl.pattern.MatchString(header.Text)
+}
+
+// isNew returns true if the header of the file is the new one of the mapping (when it has one)
+func (m *Mapping) isNew(path string, header *HeaderMatch, lang *Language, options *Options) bool {
+	if len(strings.TrimSpace(m.New)) == 0 {
+		return false
+	}
+	tmpl, err := newLicenseTemplate(path, options.renderLicense(m.New, lang), options)
+	return err == nil && tmpl.pattern.MatchString(header.Text)
+}
+
+// findMapped returns the header of the content if it is the new one of any mapping (if any)
+func (o *Options) findMapped(path, content string, lang *Language) *HeaderMatch {
+	header := findHeader(lang, content)
+	if header == nil {
+		return nil
+	}
+	for _, mapping := range o.Mappings {
+		if mapping.isNew(path, header, lang, o) {
+			return header
+		}
+	}
+	return nil
+}
+
+// findMapping returns the first mapping whose old header is the one of the content (if any)
+func (o *Options) findMapping(path, content string, lang *Language) (*Mapping, *HeaderMatch) {
+	header := findHeader(lang, content)
+	if header == nil {
+		return nil, nil
+	}
+	for _, mapping := range o.Mappings {
+		if mapping.matches(path, header, lang, o) {
+			return mapping, header
+		}
+	}
+	return nil, nil
+}
+
+// mapFile replaces the header of the content by the new one of the mapping
+func mapFile(path, content string, mapping *Mapping, header *HeaderMatch, licenses *licenseSet, lang *Language, options *Options, h fileHandler) *Operation {
+	if !options.Replace {
+		return &Operation{Action: SkippedReplace, Path: path, Header: header, Mapping: mapping.Name}
+	}
+
+	newHeader := mapping.New
+	if len(strings.TrimSpace(newHeader)) == 0 {
+		newHeader = licenses.target
+	}
+	tmpl, err := newLicenseTemplate(path, options.renderLicense(newHeader, lang), options)
+	if err != nil {
+		return &Operation{Action: OperationError, Path: path}
+	}
+	license, err := tmpl.execute()
+	if err != nil {
+		return &Operation{Action: OperationError, Path: path}
+	}
+
+	newContent := replaceHeader(lang, content, header, license, options.spacing())
+	if err := h.WriteFile(path, []byte(newContent)); err != nil {
+		return &Operation{Action: OperationError, Path: path}
+	}
+	return &Operation{Action: LicenseReplaced, Path: path, Header: header, Mapping: mapping.Name}
+}
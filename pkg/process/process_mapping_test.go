@@ -0,0 +1,110 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMappings(t *testing.T) {
+	mappings, err := ParseMappings([]byte(`[
+		{"name": "acme", "old": "Copyright Acme Corp.", "new": "Copyright Acme Inc."},
+		{"name": "legacy", "old_regex": "(?i)property of acme"}
+	]`))
+	assert.Nil(t, err)
+	assert.Len(t, mappings, 2)
+	assert.Equal(t, "Copyright Acme Corp.", mappings[0].Old)
+	assert.NotNil(t, mappings[1].oldRegex)
+
+	invalid := []string{
+		`[{"old": "Copyright Acme Corp."}]`,
+		`[{"name": "acme"}]`,
+		`[{"name": "acme", "old": "Copyright Acme Corp.", "old_regex": "acme"}]`,
+		`[{"name": "acme", "old_regex": "("}]`,
+		`{"name": "acme"}`,
+	}
+	for _, data := range invalid {
+		_, err = ParseMappings([]byte(data))
+		assert.NotNil(t, err, data)
+	}
+}
+
+func TestFile_Mappings(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	mappings, err := ParseMappings([]byte(`[
+		{"name": "acme", "old": "Copyright {{year}} Acme Corp.", "new": "Copyright 2024 Acme Inc."},
+		{"name": "legacy", "old_regex": "(?i)property of acme"}
+	]`))
+	assert.Nil(t, err)
+	options := &Options{Replace: true, Mappings: mappings}
+
+	// Headers that match a mapping are replaced by its new header
+	content := "/*\n * Copyright 2015 Acme Corp.\n */\n\npackage main\n"
+	expected := "/*\n * Copyright 2024 Acme Inc.\n */\n\npackage main\n"
+	migrated := expected
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op := FileOperation(fileName, content, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, &Operation{Action: LicenseReplaced, Path: fileName, Header: &HeaderMatch{Start: 0, End: 35, Text: content[:35]}, Mapping: "acme"}, op)
+	handler.AssertExpectations(t)
+
+	// The target license is used when the mapping has no new header
+	content = "// Property of ACME, do not distribute\n\npackage main\n"
+	expected = strings.TrimSpace(testTargetLicenseHeader) + "\n\npackage main\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = FileOperation(fileName, content, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, LicenseReplaced, op.Action)
+	assert.Equal(t, "legacy", op.Mapping)
+	handler.AssertExpectations(t)
+
+	// Other headers are replaced by the target license
+	handler.On("WriteFile", fileName, []byte(testFileWithTargetLicense)).Return(nil).Once()
+	op = FileOperation(fileName, testFileWithDifferentLicense, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, LicenseReplaced, op.Action)
+	assert.Empty(t, op.Mapping)
+	handler.AssertExpectations(t)
+
+	// Headers that are the new header of a mapping are not replaced again
+	op = FileOperation(fileName, migrated, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, LicenseOk, op.Action)
+
+	// Other headers are not replaced with options.MappingsOnly
+	options.MappingsOnly = true
+	op = FileOperation(fileName, testFileWithDifferentLicense, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, SkippedReplace, op.Action)
+	assert.Equal(t, NotMappedReason, op.Reason)
+	assert.Empty(t, op.Mapping)
+	op = FileOperation(fileName, migrated, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, LicenseOk, op.Action)
+	options.MappingsOnly = false
+
+	// Nothing is written without the -r option
+	options.Replace = false
+	op = FileOperation(fileName, content, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, SkippedReplace, op.Action)
+	assert.Equal(t, "legacy", op.Mapping)
+}
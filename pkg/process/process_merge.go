@@ -0,0 +1,116 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// MergeAbove places the copyright lines of the replaced header above the license text
+	MergeAbove = "above"
+	// MergeBelow places the copyright lines of the replaced header below the license text
+	MergeBelow = "below"
+)
+
+var (
+	// copyrightLineRegex matches the lines of a copyright notice such as "Copyright (c) 2019 The Author"
+	copyrightLineRegex = regexp.MustCompile(`(?i)^(?:copyright|\(c\)|©)(?:\W|$)`)
+	// copyrightNoiseRegex matches the parts of a copyright notice that do not identify its holder
+	copyrightNoiseRegex = regexp.MustCompile(`(?i)copyright|\(c\)|©|all rights reserved|[\d\s\-,.]+`)
+)
+
+// copyrightLines returns the copyright lines of the old header whose holders are not already in the
+// copyright lines of the new one
+func copyrightLines(oldHeader, newHeader string, languages Languages) []string {
+	holders := make(map[string]bool)
+	for _, line := range strings.Split(plainText(newHeader, languages), "\n") {
+		if line = strings.TrimSpace(line); copyrightLineRegex.MatchString(line) {
+			holders[copyrightHolder(line)] = true
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(plainText(oldHeader, languages), "\n") {
+		line = strings.TrimSpace(line)
+		if !copyrightLineRegex.MatchString(line) || holders[copyrightHolder(line)] {
+			continue
+		}
+		holders[copyrightHolder(line)] = true
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// copyrightHolder returns the normalized holder of the copyright line (without years nor symbols)
+func copyrightHolder(line string) string {
+	return strings.ToLower(copyrightNoiseRegex.ReplaceAllString(line, ""))
+}
+
+// isMerged returns true if the header is the merged one, regardless of the years of its copyright notices
+func isMerged(header, merged string) bool {
+	re, err := regexp.Compile(`^` + quoteYearInsensitive(strings.TrimSpace(merged)) + `$`)
+	return err == nil && re.MatchString(strings.TrimSpace(header))
+}
+
+// mergeCopyrights returns the header with the copyright lines placed above or below its text.
+// The lines are commented with the same syntax as the header, which is rendered again from its
+// plain text when its comment delimiters are not on their own lines.
+func (l *Language) mergeCopyrights(header string, copyrights []string, position string) string {
+	header = strings.TrimSpace(header)
+	lines := strings.Split(header, "\n")
+
+	// start and end are the bounds of the lines with the text of the header
+	start, end, prefix := 0, len(lines), ""
+	switch {
+	case len(l.BlockStart) > 0 && strings.HasPrefix(header, l.BlockStart):
+		if len(lines) < 3 || strings.TrimSpace(lines[0]) != l.BlockStart || strings.TrimSpace(lines[len(lines)-1]) != l.BlockEnd {
+			text, _ := l.uncomment(header)
+			return l.render(mergeText(strings.Split(text, "\n"), copyrights, "", position))
+		}
+		start, end, prefix = 1, len(lines)-1, l.BlockPrefix
+	case len(l.LinePrefix) > 0 && strings.HasPrefix(header, l.LinePrefix):
+		prefix = l.LinePrefix + " "
+	}
+
+	merged := mergeText(lines[start:end], copyrights, prefix, position)
+	return strings.Join(append(append(lines[:start:start], merged), lines[end:]...), "\n")
+}
+
+// mergeText returns the lines with the copyright lines (prefixed) placed above or below them.
+// The copyright lines placed below are separated from the text by an empty line.
+func mergeText(lines []string, copyrights []string, prefix string, position string) string {
+	prefixed := make([]string, len(copyrights))
+	for i, copyright := range copyrights {
+		prefixed[i] = strings.TrimRight(prefix+copyright, " \t")
+	}
+	merged := make([]string, 0, len(lines)+len(prefixed)+1)
+	if position == MergeBelow {
+		merged = append(append(merged, lines...), strings.TrimRight(prefix, " \t"))
+		return strings.Join(append(merged, prefixed...), "\n")
+	}
+	merged = append(append(merged, prefixed...), lines...)
+	return strings.Join(merged, "\n")
+}
@@ -0,0 +1,98 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyrightLines(t *testing.T) {
+	languages := DefaultLanguages()
+	oldHeader := "/*\n * Copyright (c) 2019 Contributor\n * Copyright 2018, 2019 The Author. All rights reserved.\n * (c) 2020 Another Contributor\n * Licensed under the Apache License 2.0\n */"
+	newHeader := "/*\n * Copyright (c) 2024 The Author\n *\n * Licensed under the MIT License\n */"
+	expected := []string{"Copyright (c) 2019 Contributor", "(c) 2020 Another Contributor"}
+	assert.Equal(t, expected, copyrightLines(oldHeader, newHeader, languages))
+
+	// Line comments and duplicated holders
+	oldHeader = "# Copyright 2019 Contributor\n# Copyright 2020 Contributor\n# Copyrighted material"
+	assert.Equal(t, []string{"Copyright 2019 Contributor"}, copyrightLines(oldHeader, newHeader, languages))
+
+	assert.Empty(t, copyrightLines("/* Licensed under the Apache License 2.0 */", newHeader, languages))
+}
+
+func TestMergeCopyrights(t *testing.T) {
+	languages := DefaultLanguages()
+	golang := languages.Find("main.go")
+	copyrights := []string{"Copyright (c) 2019 Contributor"}
+
+	header := "/*\n * Copyright (c) 2024 The Author\n *\n * Licensed under the MIT License\n */"
+	expected := "/*\n * Copyright (c) 2019 Contributor\n * Copyright (c) 2024 The Author\n *\n * Licensed under the MIT License\n */"
+	assert.Equal(t, expected, golang.mergeCopyrights(header, copyrights, MergeAbove))
+
+	expected = "/*\n * Copyright (c) 2024 The Author\n *\n * Licensed under the MIT License\n *\n * Copyright (c) 2019 Contributor\n */"
+	assert.Equal(t, expected, golang.mergeCopyrights(header, copyrights, MergeBelow))
+
+	// Line comments
+	header = "# Copyright (c) 2024 The Author\n# Licensed under the MIT License"
+	expected = "# Copyright (c) 2019 Contributor\n# Copyright (c) 2024 The Author\n# Licensed under the MIT License"
+	assert.Equal(t, expected, languages.Find("main.py").mergeCopyrights(header, copyrights, MergeAbove))
+
+	// Block comments with text on the delimiter lines are rendered again
+	header = "/* Copyright (c) 2024 The Author */"
+	expected = "/*\n * Copyright (c) 2024 The Author\n *\n * Copyright (c) 2019 Contributor\n */"
+	assert.Equal(t, expected, golang.mergeCopyrights(header, copyrights, MergeBelow))
+}
+
+func TestFile_MergeCopyrights(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	options := &Options{Replace: true, MergeCopyrights: MergeAbove}
+	license := "Copyright (c) 2024 The Author\n\nLicensed under the MIT License"
+	content := "/*\n * Copyright (c) 2019 Contributor\n * Licensed under the Apache License 2.0\n */\n\npackage main\n"
+	expected := "/*\n * Copyright (c) 2019 Contributor\n * Copyright (c) 2024 The Author\n *\n * Licensed under the MIT License\n */\n\npackage main\n"
+
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op := File(fileName, content, license, options, handler)
+	assert.Equal(t, LicenseMerged, op)
+	handler.AssertExpectations(t)
+
+	// The merged header is ok even if the years of its copyright notices change
+	op = File(fileName, expected, license, options, handler)
+	assert.Equal(t, LicenseOk, op)
+	op = File(fileName, expected, "Copyright (c) 2025 The Author\n\nLicensed under the MIT License", options, handler)
+	assert.Equal(t, LicenseOk, op)
+
+	// Headers without other copyright lines are replaced as usual
+	handler.On("WriteFile", fileName, []byte("/*\n * Copyright (c) 2024 The Author\n *\n * Licensed under the MIT License\n */\n\npackage main\n")).Return(nil).Once()
+	op = File(fileName, "/* Copyright (c) 2019 The Author */\n\npackage main\n", license, options, handler)
+	assert.Equal(t, LicenseReplaced, op)
+	handler.AssertExpectations(t)
+
+	// Nothing is written without the -r option
+	options.Replace = false
+	op = File(fileName, content, license, options, handler)
+	assert.Equal(t, SkippedReplace, op)
+}
@@ -0,0 +1,183 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// NormalizeLineEndings ignores the differences between CRLF and LF line endings
+	NormalizeLineEndings = "line_endings"
+	// NormalizeWhitespace ignores trailing spaces and the differences between tabs and (multiple) spaces
+	NormalizeWhitespace = "whitespace"
+	// NormalizeReflow ignores how the paragraphs of the header are wrapped
+	NormalizeReflow = "reflow"
+	// NormalizeUnicode ignores the differences between the NFC and NFD Unicode forms
+	NormalizeUnicode = "unicode"
+)
+
+// NormalizeLevels are all the normalizations that can be applied when comparing headers
+var NormalizeLevels = []string{NormalizeLineEndings, NormalizeWhitespace, NormalizeReflow, NormalizeUnicode}
+
+// spacesRegex matches runs of spaces and tabs
+var spacesRegex = regexp.MustCompile(`[ \t]+`)
+
+// ParseNormalize returns the normalization levels of a comma separated list (all for every level)
+func ParseNormalize(list string) ([]string, error) {
+	var levels []string
+	for _, level := range strings.Split(list, ",") {
+		level = strings.TrimSpace(level)
+		switch {
+		case len(level) == 0:
+		case level == "all":
+			levels = append(levels, NormalizeLevels...)
+		case contains(NormalizeLevels, level):
+			levels = append(levels, level)
+		default:
+			return nil, fmt.Errorf("unknown normalization level: %s", level)
+		}
+	}
+	return levels, nil
+}
+
+// normalizes returns true if the normalization level has been enabled in the options
+func (o *Options) normalizes(level string) bool {
+	return contains(o.Normalize, level)
+}
+
+// normalize returns the text with the normalizations enabled in the options applied
+func (o *Options) normalize(text string, lang *Language) string {
+	if o.normalizes(NormalizeLineEndings) {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	if o.normalizes(NormalizeUnicode) {
+		text = norm.NFC.String(text)
+	}
+	if o.normalizes(NormalizeReflow) {
+		if plain, ok := lang.uncomment(text); ok {
+			text = plain
+		}
+		text = reflow(text)
+	}
+	if o.normalizes(NormalizeWhitespace) {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(spacesRegex.ReplaceAllString(line, " "), " ")
+		}
+		text = strings.Join(lines, "\n")
+	}
+	return text
+}
+
+// reflow returns the text with the lines of each paragraph joined by spaces
+func reflow(text string) string {
+	var paragraphs, lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+			continue
+		}
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, strings.Join(lines, " "))
+			lines = nil
+		}
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, " "))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// matchesNormalized returns true if the header is the license once both are normalized
+func (o *Options) matchesNormalized(path, header, license string, lang *Language) bool {
+	if len(o.Normalize) == 0 {
+		return false
+	}
+	tmpl, err := newLicenseTemplate(path, o.normalize(o.renderLicense(license, lang), lang), o)
+	if err != nil {
+		return false
+	}
+	re, err := regexp.Compile(`^(?:` + tmpl.pattern.String() + `)$`)
+	return err == nil && re.MatchString(o.normalize(strings.TrimSpace(header), lang))
+}
+
+// crlfHandler is a file handler that writes the lines changed in a file with CRLF line endings,
+// leaving the rest of its original content untouched
+type crlfHandler struct {
+	fileHandler
+	original string
+}
+
+func (h crlfHandler) WriteFile(name string, content []byte) error {
+	return h.fileHandler.WriteFile(name, []byte(withCRLF(h.original, string(content))))
+}
+
+// withCRLF converts to CRLF the LF line endings of the region of the content that differs
+// from the original one (the common prefix and suffix are kept as they are)
+func withCRLF(original, content string) string {
+	prefix := 0
+	for prefix < len(original) && prefix < len(content) && original[prefix] == content[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(original)-prefix && suffix < len(content)-prefix &&
+		original[len(original)-1-suffix] == content[len(content)-1-suffix] {
+		suffix++
+	}
+	end := len(content) - suffix
+
+	var b strings.Builder
+	b.WriteString(content[:prefix])
+	for i := prefix; i < len(content); i++ {
+		// The line ending right after the changed region belongs to its last line
+		if i > end || (i == end && i == prefix) {
+			b.WriteString(content[i:])
+			break
+		}
+		if content[i] == '\n' && (i == 0 || content[i-1] != '\r') {
+			b.WriteByte('\r')
+		}
+		b.WriteByte(content[i])
+	}
+	return b.String()
+}
+
+// usesCRLF returns true if most of the lines of the content end with CRLF
+func usesCRLF(content string) bool {
+	return strings.Count(content, "\r\n")*2 > strings.Count(content, "\n")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNormalize(t *testing.T) {
+	levels, err := ParseNormalize("whitespace, reflow")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{NormalizeWhitespace, NormalizeReflow}, levels)
+
+	levels, err = ParseNormalize("all")
+	assert.Nil(t, err)
+	assert.Equal(t, NormalizeLevels, levels)
+
+	levels, err = ParseNormalize("")
+	assert.Nil(t, err)
+	assert.Empty(t, levels)
+
+	_, err = ParseNormalize("whitespace,unknown")
+	assert.NotNil(t, err)
+}
+
+func TestNormalize(t *testing.T) {
+	golang := DefaultLanguages().Find("main.go")
+
+	options := &Options{Normalize: []string{NormalizeLineEndings}}
+	assert.Equal(t, "/*\n * License\n */", options.normalize("/*\r\n * License\r\n */", golang))
+
+	options = &Options{Normalize: []string{NormalizeWhitespace}}
+	assert.Equal(t, "/*\n * Licensed under\n * the MIT License\n */", options.normalize("/*\n *  Licensed\tunder  \n\t* the MIT License\n */", golang))
+
+	options = &Options{Normalize: []string{NormalizeReflow}}
+	text := "/*\n * Copyright 2020\n * The Author\n *\n * Licensed under the\n * MIT License\n */"
+	assert.Equal(t, "Copyright 2020 The Author\n\nLicensed under the MIT License", options.normalize(text, golang))
+
+	// "\u00ed" is the NFC form of "i\u0301"
+	options = &Options{Normalize: []string{NormalizeUnicode}}
+	assert.Equal(t, "/* Llu\u00eds */", options.normalize("/* Llui\u0301s */", golang))
+}
+
+func TestFile_Normalize(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	// "\u00e9" is the NFC form of "e\u0301"
+	license := "/*\n * Copyright (c) 2020 The Author.\n *\n * Licensed under the MIT Licens\u00e9.\n */"
+	headers := map[string]string{
+		NormalizeLineEndings: "/*\r\n * Copyright (c) 2020 The Author.\r\n *\r\n * Licensed under the MIT Licens\u00e9.\r\n */",
+		NormalizeWhitespace:  "/*\n *  Copyright (c) 2020\tThe Author.  \n *\n * Licensed under the MIT Licens\u00e9.\n */",
+		NormalizeReflow:      "/*\n * Copyright (c) 2020 The\n * Author.\n *\n * Licensed under the MIT\n * Licens\u00e9.\n */",
+		NormalizeUnicode:     "/*\n * Copyright (c) 2020 The Author.\n *\n * Licensed under the MIT Licens\u0065\u0301.\n */",
+	}
+
+	for level, header := range headers {
+		content := header + "\n\npackage main\n"
+
+		// The header is only ok with the normalization level
+		op := File(fileName, content, license, &Options{Normalize: []string{level}}, handler)
+		assert.Equal(t, LicenseOk, op, level)
+		op = File(fileName, content, license, &Options{}, handler)
+		assert.Equal(t, SkippedReplace, op, level)
+	}
+
+	// Other headers are not ok
+	op := File(fileName, testFileWithDifferentLicense, license, &Options{Normalize: NormalizeLevels}, handler)
+	assert.Equal(t, SkippedReplace, op)
+}
+
+func TestFile_KeepLineEndings(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	options := &Options{Add: true}
+
+	content := "package main\r\n\r\nfunc main() {}\r\n"
+	expected := "/* Copyright (c) 2020 The Author */\r\n\r\npackage main\r\n\r\nfunc main() {}\r\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op := File(fileName, content, "/* Copyright (c) 2020 The Author */", options, handler)
+	assert.Equal(t, LicenseAdded, op)
+	handler.AssertExpectations(t)
+
+	// Only the lines that are changed use the dominant line endings of the file
+	content = "package main\r\n\r\n// Mixed\nfunc main() {}\r\n"
+	expected = "/* Copyright (c) 2020 The Author */\r\n\r\npackage main\r\n\r\n// Mixed\nfunc main() {}\r\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = File(fileName, content, "/* Copyright (c) 2020 The Author */", options, handler)
+	assert.Equal(t, LicenseAdded, op)
+	handler.AssertExpectations(t)
+
+	content = "package main\n\n// Mixed\r\nfunc main() {}\n"
+	expected = "/* Copyright (c) 2020 The Author */\n\npackage main\n\n// Mixed\r\nfunc main() {}\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = File(fileName, content, "/* Copyright (c) 2020 The Author */", options, handler)
+	assert.Equal(t, LicenseAdded, op)
+	handler.AssertExpectations(t)
+
+	// LF files are not changed
+	content = "package main\n"
+	handler.On("WriteFile", fileName, []byte("/* Copyright (c) 2020 The Author */\n\npackage main\n")).Return(nil).Once()
+	op = File(fileName, content, "/* Copyright (c) 2020 The Author */", options, handler)
+	assert.Equal(t, LicenseAdded, op)
+	handler.AssertExpectations(t)
+}
+
+func TestWithCRLF(t *testing.T) {
+	tests := []struct {
+		original, content, expected string
+	}{
+		// Inserted lines
+		{"a\r\nb\r\n", "h\n\na\r\nb\r\n", "h\r\n\r\na\r\nb\r\n"},
+		{"a\r\nb\n", "a\r\nh\nb\n", "a\r\nh\r\nb\n"},
+		{"p\r\n", "p\r\nh\n", "p\r\nh\r\n"},
+		// Replaced lines
+		{"// old\r\nb\n", "// new\nb\n", "// new\r\nb\n"},
+		// Removed lines
+		{"h\r\na\nb\r\n", "a\nb\r\n", "a\nb\r\n"},
+		// Unchanged content
+		{"a\nb\r\n", "a\nb\r\n", "a\nb\r\n"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, withCRLF(test.original, test.content))
+	}
+}
@@ -0,0 +1,58 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+// removeFile processes one file in remove mode, where the leading license header is removed
+// if it matches the target license (or any license header if options.RemoveAny is true). The
+// file is only changed if options.Replace is true.
+func removeFile(path string, content string, licenses *licenseSet, options *Options, h fileHandler) *Operation {
+	lang := options.fileLanguage(path, content)
+	match := options.licenseHeader(lang, content)
+	if match == nil {
+		return &Operation{Action: NoHeader, Path: path}
+	}
+
+	if !options.RemoveAny {
+		tmpl, err := newLicenseTemplate(path, options.renderLicense(licenses.target, lang), options)
+		if err != nil {
+			return &Operation{Action: OperationError, Path: path}
+		}
+		// The header is compared with the target license like in the other modes (e.g. ignoring line endings)
+		loc := tmpl.pattern.FindStringIndex(content)
+		inHeader := loc != nil && loc[0] >= match.Start && loc[1] <= match.End
+		if !inHeader && !options.matchesNormalized(path, match.Text, licenses.target, lang) {
+			return &Operation{Action: NoHeader, Path: path}
+		}
+	}
+
+	if !options.Replace {
+		return &Operation{Action: SkippedRemove, Path: path, Header: match}
+	}
+
+	newContent := removeRegion(content, match.Start, match.End)
+	if err := h.WriteFile(path, []byte(newContent)); err != nil {
+		return &Operation{Action: OperationError, Path: path}
+	}
+	return &Operation{Action: LicenseRemoved, Path: path, Header: match}
+}
@@ -0,0 +1,112 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFile_Remove(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	options := &Options{Remove: true, Replace: true}
+
+	// The target license is removed
+	handler.On("WriteFile", fileName, []byte(testFileWithoutLicense)).Return(nil).Once()
+	op := File(fileName, testFileWithTargetLicense, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, LicenseRemoved, op)
+	handler.AssertExpectations(t)
+
+	// Other licenses are not removed
+	op = File(fileName, testFileWithDifferentLicense, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, NoHeader, op)
+
+	// Files without license are not changed
+	op = File(fileName, testFileWithoutLicense, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, NoHeader, op)
+
+	// The target license is only removed from the leading comments
+	op = File(fileName, testFileWithoutLicense+"\n"+testTargetLicenseHeader, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, NoHeader, op)
+
+	// The differences ignored by options.Normalize are ignored when removing the license too
+	crlf := strings.ReplaceAll(testFileWithTargetLicense, "\n", "\r\n")
+	handler.On("WriteFile", fileName, []byte(strings.ReplaceAll(testFileWithoutLicense, "\n", "\r\n"))).Return(nil).Once()
+	op = File(fileName, crlf, testTargetLicenseHeader, &Options{Remove: true, Replace: true, Normalize: []string{NormalizeLineEndings}}, handler)
+	assert.Equal(t, LicenseRemoved, op)
+	handler.AssertExpectations(t)
+	op = File(fileName, crlf, testTargetLicenseHeader, &Options{Remove: true, Replace: true, Normalize: []string{}}, handler)
+	assert.Equal(t, NoHeader, op)
+
+	// Any license is removed with options.RemoveAny
+	options = &Options{RemoveAny: true, Replace: true}
+	handler.On("WriteFile", fileName, []byte(testFileWithoutLicense)).Return(nil).Once()
+	op = File(fileName, testFileWithDifferentLicense, "", options, handler)
+	assert.Equal(t, LicenseRemoved, op)
+	handler.AssertExpectations(t)
+
+	// Comments that are not licenses are not removed
+	op = File(fileName, "// Package main\npackage main\n", "", options, handler)
+	assert.Equal(t, NoHeader, op)
+
+	// The preamble is kept
+	fileName = "main.py"
+	handler.On("WriteFile", fileName, []byte("#!/usr/bin/env python\n\nprint()\n")).Return(nil).Once()
+	op = File(fileName, "#!/usr/bin/env python\n\n# Copyright 2019 The Author\n\nprint()\n", "", options, handler)
+	assert.Equal(t, LicenseRemoved, op)
+	handler.AssertExpectations(t)
+
+	// The file is not changed without options.Replace
+	op = File(fileName, "# Copyright 2019 The Author\n\nprint()\n", "", &Options{RemoveAny: true}, handler)
+	assert.Equal(t, SkippedRemove, op)
+
+	// Errors writing the file are reported
+	handler.On("WriteFile", fileName, []byte("print()\n")).Return(errors.New("error")).Once()
+	op = File(fileName, "# Copyright 2019 The Author\n\nprint()\n", "", options, handler)
+	assert.Equal(t, OperationError, op)
+	handler.AssertExpectations(t)
+}
+
+func TestFiles_RemoveAny(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{RemoveAny: true, Replace: true, Extensions: []string{".go"}}
+
+	// The license header file is not read when removing any license
+	handler.pathsToWalk = []string{"main.go", "other.go"}
+	handler.On("WalkDir", options.Path, mock.Anything).Return(nil).Once()
+	handler.On("ReadFile", "main.go").Return([]byte(testFileWithDifferentLicense), nil).Once()
+	handler.On("ReadFile", "other.go").Return([]byte(testFileWithoutLicense), nil).Once()
+	handler.On("WriteFile", "main.go", []byte(testFileWithoutLicense)).Return(nil).Once()
+
+	stats, err := Files(options, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"main.go"}, stats.Files[LicenseRemoved])
+	assert.Equal(t, []string{"other.go"}, stats.Files[NoHeader])
+	handler.AssertExpectations(t)
+}
@@ -0,0 +1,149 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"strings"
+)
+
+// renderLicense returns the license header to be used in the files of the language.
+//
+// Licenses already commented with the syntax of the language (or any license when a custom
+// header regex is provided) are used verbatim. Otherwise, the license is converted to plain
+// text (if it was commented with the syntax of another language) and rendered as a comment
+// of the language.
+func (o *Options) renderLicense(license string, lang *Language) string {
+	license = strings.TrimSpace(license)
+	if o.HeaderRegex != nil {
+		return license
+	}
+	if _, ok := lang.uncomment(license); ok {
+		return license
+	}
+	return lang.render(plainText(license, o.languages()))
+}
+
+// plainText returns the text of the license without the comment syntax of any of the languages
+func plainText(license string, languages Languages) string {
+	for _, lang := range languages {
+		if text, ok := lang.uncomment(license); ok {
+			return text
+		}
+	}
+	text, _ := defaultLanguage.uncomment(license)
+	return text
+}
+
+// render returns the plain text as a comment of the language. Block comments are preferred
+// over line comments when the language supports both.
+func (l *Language) render(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	var sb strings.Builder
+
+	if len(l.BlockStart) == 0 {
+		for i, line := range lines {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(strings.TrimRight(l.LinePrefix+" "+line, " \t\r"))
+		}
+		return sb.String()
+	}
+
+	sb.WriteString(l.BlockStart + "\n")
+	for _, line := range lines {
+		sb.WriteString(strings.TrimRight(l.BlockPrefix+line, " \t\r") + "\n")
+	}
+	if len(strings.TrimSpace(l.BlockPrefix)) > 0 {
+		indent := l.BlockPrefix[:len(l.BlockPrefix)-len(strings.TrimLeft(l.BlockPrefix, " \t"))]
+		sb.WriteString(indent)
+	}
+	sb.WriteString(l.BlockEnd)
+	return sb.String()
+}
+
+// uncomment returns the text without the comment syntax of the language and true if the whole
+// text was a comment of the language. Otherwise, it returns the text as it is and false.
+func (l *Language) uncomment(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if len(l.BlockStart) > 0 && strings.HasPrefix(text, l.BlockStart) && strings.HasSuffix(text, l.BlockEnd) &&
+		len(text) >= len(l.BlockStart)+len(l.BlockEnd) && strings.Index(text, l.BlockEnd) == len(text)-len(l.BlockEnd) {
+		lines := strings.Split(text[len(l.BlockStart):len(text)-len(l.BlockEnd)], "\n")
+		lines[0] = strings.TrimLeft(lines[0], " \t")
+		return trimLines(stripLinePrefix(lines, strings.TrimSpace(l.BlockPrefix), true)), true
+	}
+	if len(l.LinePrefix) > 0 {
+		lines := strings.Split(text, "\n")
+		for _, line := range lines {
+			if !strings.HasPrefix(strings.TrimSpace(line), l.LinePrefix) {
+				return text, false
+			}
+		}
+		return trimLines(stripLinePrefix(lines, l.LinePrefix, false)), true
+	}
+	return text, false
+}
+
+// stripLinePrefix removes the prefix (and the space after it) from the lines. If optional is true,
+// the prefix is only removed when all the non-empty lines but the first one start with it.
+func stripLinePrefix(lines []string, prefix string, optional bool) []string {
+	if len(prefix) == 0 {
+		return lines
+	}
+	if optional {
+		for i, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if i > 0 && len(trimmed) > 0 && !strings.HasPrefix(trimmed, prefix) {
+				return lines
+			}
+		}
+	}
+	stripped := make([]string, len(lines))
+	for i, line := range lines {
+		line = strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(line, prefix) {
+			line = strings.TrimPrefix(strings.TrimPrefix(line, prefix), " ")
+		}
+		stripped[i] = line
+	}
+	return stripped
+}
+
+// trimLines joins the lines removing the trailing spaces of each one and the leading and
+// trailing empty lines
+func trimLines(lines []string) string {
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// renderLine returns the single line text as a comment of the language. Line comments are
+// preferred over block comments when the language supports both.
+func (l *Language) renderLine(text string) string {
+	if len(l.LinePrefix) > 0 {
+		return l.LinePrefix + " " + text
+	}
+	return l.BlockStart + " " + text + " " + l.BlockEnd
+}
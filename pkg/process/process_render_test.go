@@ -0,0 +1,100 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPlainLicense = "Copyright (c) 2020 The Author\n\nLicensed under the MIT License."
+
+func TestRender(t *testing.T) {
+	languages := DefaultLanguages()
+
+	expected := "/*\n * Copyright (c) 2020 The Author\n *\n * Licensed under the MIT License.\n */"
+	assert.Equal(t, expected, languages.Find("main.go").render(testPlainLicense))
+
+	expected = "# Copyright (c) 2020 The Author\n#\n# Licensed under the MIT License."
+	assert.Equal(t, expected, languages.Find("main.py").render(testPlainLicense))
+
+	expected = "<!--\n  Copyright (c) 2020 The Author\n\n  Licensed under the MIT License.\n-->"
+	assert.Equal(t, expected, languages.Find("index.html").render(testPlainLicense))
+
+	expected = "{-\nCopyright (c) 2020 The Author\n\nLicensed under the MIT License.\n-}"
+	assert.Equal(t, expected, languages.Find("Main.hs").render(testPlainLicense))
+}
+
+func TestUncomment(t *testing.T) {
+	languages := DefaultLanguages()
+	golang := languages.Find("main.go")
+	python := languages.Find("main.py")
+
+	text, ok := golang.uncomment("/*\n * Copyright (c) 2020 The Author\n *\n * Licensed under the MIT License.\n */")
+	assert.True(t, ok)
+	assert.Equal(t, testPlainLicense, text)
+
+	text, ok = golang.uncomment("/* Copyright (c) 2020 The Author\n\nLicensed under the MIT License.\n*/")
+	assert.True(t, ok)
+	assert.Equal(t, testPlainLicense, text)
+
+	text, ok = golang.uncomment("// Copyright (c) 2020 The Author\n//\n// Licensed under the MIT License.")
+	assert.True(t, ok)
+	assert.Equal(t, testPlainLicense, text)
+
+	text, ok = python.uncomment("# Copyright (c) 2020 The Author\n#\n# Licensed under the MIT License.\n")
+	assert.True(t, ok)
+	assert.Equal(t, testPlainLicense, text)
+
+	_, ok = python.uncomment("/* Copyright (c) 2020 The Author */")
+	assert.False(t, ok)
+
+	_, ok = golang.uncomment("/* Copyright */ package main /* License */")
+	assert.False(t, ok)
+
+	_, ok = golang.uncomment(testPlainLicense)
+	assert.False(t, ok)
+}
+
+func TestRenderLicense(t *testing.T) {
+	options := &Options{}
+	golang := options.language("main.go")
+	python := options.language("main.py")
+
+	// Licenses already commented with the syntax of the language are used verbatim
+	assert.Equal(t, "/* Copyright (c) 2020 The Author */", options.renderLicense("/* Copyright (c) 2020 The Author */\n", golang))
+
+	// Plain text licenses are rendered
+	assert.Equal(t, golang.render(testPlainLicense), options.renderLicense(testPlainLicense, golang))
+	assert.Equal(t, python.render(testPlainLicense), options.renderLicense(testPlainLicense, python))
+
+	// Licenses commented with the syntax of another language are rendered
+	assert.Equal(t, python.render(testPlainLicense), options.renderLicense(golang.render(testPlainLicense), python))
+
+	// Licenses are used verbatim with custom header regex
+	options.HeaderRegex = regexp.MustCompile(`"""(.|[\r\n])*"""`)
+	assert.Equal(t, `"""License"""`, options.renderLicense(`"""License"""`, options.language("main.py")))
+}
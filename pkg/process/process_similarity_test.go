@@ -0,0 +1,79 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, similarity("Copyright (c) 2020 The Author", "Copyright (C) 2020, the author."))
+	assert.Equal(t, 1.0, similarity("", ""))
+	assert.Equal(t, 0.0, similarity("Copyright The Author", ""))
+	assert.Equal(t, 0.0, similarity("Copyright The Author", "MIT License"))
+
+	// One typo in a header of 10 words
+	score := similarity("Licensed under the MIT License, see the LICENSE file for details",
+		"Licensed under the MIT Licence, see the LICENSE file for details")
+	assert.InDelta(t, 0.909, score, 0.001)
+
+	// Words in a different order
+	assert.InDelta(t, 0.5, similarity("a b c d", "c d a b"), 0.001)
+}
+
+func TestFile_Similarity(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	license := "/*\n * Copyright (c) 2020 The Author.\n *\n * Licensed under the MIT License, see the LICENSE file for details.\n */"
+	typo := "/*\n * Copyright (c) 2020 The Author.\n *\n * Licensed under the MIT Licence, see the LICENSE file for details.\n */\n\npackage main\n"
+	foreign := "/*\n * Copyright (c) 2019 Another Author.\n *\n * Licensed under the Apache License, Version 2.0.\n */\n\npackage main\n"
+
+	// Headers get a score even without thresholds
+	op := FileOperation(fileName, typo, license, &Options{}, handler)
+	assert.Equal(t, SkippedReplace, op.Action)
+	assert.InDelta(t, 0.94, *op.Score, 0.01)
+
+	// Files without a header have no score
+	op = FileOperation(fileName, "package main\n", license, &Options{}, handler)
+	assert.Equal(t, SkippedAdd, op.Action)
+	assert.Nil(t, op.Score)
+
+	// Headers above the ok threshold are ok
+	op = FileOperation(fileName, typo, license, &Options{OkSimilarity: 0.9}, handler)
+	assert.Equal(t, LicenseOk, op.Action)
+
+	// Headers below the foreign threshold are never replaced
+	options := &Options{Replace: true, OkSimilarity: 0.95, ForeignSimilarity: 0.5}
+	op = FileOperation(fileName, foreign, license, options, handler)
+	assert.Equal(t, ForeignLicense, op.Action)
+	assert.Less(t, *op.Score, 0.5)
+
+	// Headers between both thresholds are near misses that can be fixed
+	handler.On("WriteFile", fileName, []byte(license+"\n\npackage main\n")).Return(nil).Once()
+	op = FileOperation(fileName, typo, license, options, handler)
+	assert.Equal(t, LicenseReplaced, op.Action)
+	handler.AssertExpectations(t)
+}
@@ -0,0 +1,259 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// spdxTag is the tag of the line with the SPDX license expression
+const spdxTag = "SPDX-License-Identifier:"
+
+var (
+	// spdxLineRegex matches the line with the SPDX license expression, capturing the expression
+	spdxLineRegex = regexp.MustCompile(`(?m)` + spdxTag + `[ \t]*([^\r\n]*)`)
+	// spdxIDRegex matches license and exception identifiers (e.g. Apache-2.0, LicenseRef-Acme or GPL-2.0+)
+	spdxIDRegex = regexp.MustCompile(`^(DocumentRef-[A-Za-z0-9.\-]+:)?[A-Za-z0-9.\-]+\+?$`)
+)
+
+// SPDXExpression is a parsed SPDX license expression such as "Apache-2.0 OR MIT"
+type SPDXExpression struct {
+	// ID is the license (or exception) identifier of the expression if it has no operator
+	ID string
+	// Operator is AND, OR or WITH
+	Operator string
+	// Left and Right are the operands of the operator
+	Left, Right *SPDXExpression
+}
+
+// ParseSPDX parses an SPDX license expression validating its syntax. Operators can be
+// written in upper or lower case and AND takes precedence over OR.
+func ParseSPDX(expression string) (*SPDXExpression, error) {
+	p := &spdxParser{tokens: spdxTokens(expression)}
+	if len(p.tokens) == 0 {
+		return nil, errors.New("empty SPDX license expression")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid SPDX license expression %q: unexpected %q", expression, p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// String returns the canonical form of the expression
+func (e *SPDXExpression) String() string {
+	if len(e.Operator) == 0 {
+		return e.ID
+	}
+	return e.Left.operand(e.Operator) + " " + e.Operator + " " + e.Right.operand(e.Operator)
+}
+
+// Equal returns true if both expressions are the same. Identifiers are compared ignoring the case.
+func (e *SPDXExpression) Equal(other *SPDXExpression) bool {
+	if e == nil || other == nil {
+		return e == other
+	}
+	if e.Operator != other.Operator {
+		return false
+	}
+	if len(e.Operator) == 0 {
+		return strings.EqualFold(e.ID, other.ID)
+	}
+	return e.Left.Equal(other.Left) && e.Right.Equal(other.Right)
+}
+
+// operand returns the expression as an operand of the parent operator (between parenthesis if needed)
+func (e *SPDXExpression) operand(parent string) string {
+	if len(e.Operator) > 0 && spdxPrecedence(e.Operator) < spdxPrecedence(parent) {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+func spdxPrecedence(operator string) int {
+	switch operator {
+	case "WITH":
+		return 3
+	case "AND":
+		return 2
+	default:
+		return 1
+	}
+}
+
+func spdxTokens(expression string) []string {
+	expression = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression)
+	return strings.Fields(expression)
+}
+
+type spdxParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *spdxParser) operator(operator string) bool {
+	if p.pos < len(p.tokens) && (p.tokens[p.pos] == operator || p.tokens[p.pos] == strings.ToLower(operator)) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *spdxParser) parseOr() (*SPDXExpression, error) {
+	return p.parseBinary("OR", p.parseAnd)
+}
+
+func (p *spdxParser) parseAnd() (*SPDXExpression, error) {
+	return p.parseBinary("AND", p.parseWith)
+}
+
+func (p *spdxParser) parseBinary(operator string, operand func() (*SPDXExpression, error)) (*SPDXExpression, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.operator(operator) {
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &SPDXExpression{Operator: operator, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *spdxParser) parseWith() (*SPDXExpression, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.operator("WITH") {
+		return left, nil
+	}
+	if len(left.Operator) > 0 {
+		return nil, errors.New("invalid SPDX license expression: WITH must follow a license identifier")
+	}
+	exception, err := p.parseID()
+	if err != nil {
+		return nil, err
+	}
+	return &SPDXExpression{Operator: "WITH", Left: left, Right: exception}, nil
+}
+
+func (p *spdxParser) parsePrimary() (*SPDXExpression, error) {
+	if p.pos < len(p.tokens) && p.tokens[p.pos] == "(" {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return nil, errors.New("invalid SPDX license expression: missing closing parenthesis")
+		}
+		p.pos++
+		return expr, nil
+	}
+	return p.parseID()
+}
+
+func (p *spdxParser) parseID() (*SPDXExpression, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("invalid SPDX license expression: missing license identifier")
+	}
+	token := p.tokens[p.pos]
+	switch strings.ToUpper(token) {
+	case "AND", "OR", "WITH", "(", ")":
+		return nil, fmt.Errorf("invalid SPDX license expression: unexpected %q", token)
+	}
+	if !spdxIDRegex.MatchString(token) {
+		return nil, fmt.Errorf("invalid SPDX license identifier %q", token)
+	}
+	p.pos++
+	return &SPDXExpression{ID: token}, nil
+}
+
+// findSPDX returns the bounds of the SPDX license expression found in the leading comments of the content
+func findSPDX(lang *Language, content string) (int, int, bool) {
+	loc := spdxLineRegex.FindStringSubmatchIndex(content[:leadingCommentsEnd(lang, content)])
+	if loc == nil {
+		return 0, 0, false
+	}
+
+	// loc[2] and loc[3] are the bounds of the expression, which may be followed by the end of a block comment
+	start, end := loc[2], loc[3]
+	value := strings.TrimRight(content[start:end], " \t")
+	if len(lang.BlockEnd) > 0 && strings.HasSuffix(value, lang.BlockEnd) {
+		value = strings.TrimRight(strings.TrimSuffix(value, lang.BlockEnd), " \t")
+	}
+	return start, start + len(value), true
+}
+
+// spdxFile processes one file in SPDX mode, where the target license is the SPDX
+// license expression of the options instead of a license header
+func spdxFile(path string, content string, licenses *licenseSet, options *Options, h fileHandler) *Operation {
+	expected, err := ParseSPDX(options.SPDX)
+	if err != nil {
+		return &Operation{Action: OperationError, Path: path}
+	}
+
+	lang := options.fileLanguage(path, content)
+	start, end, found := findSPDX(lang, content)
+	var match *HeaderMatch
+	if found {
+		match = &HeaderMatch{Start: start, End: end, Text: content[start:end]}
+		if expression, err := ParseSPDX(match.Text); err == nil && expression.Equal(expected) {
+			return &Operation{Action: LicenseOk, Path: path, Header: match}
+		}
+	}
+
+	if name, ok := licenses.matchAllowed(path, content, lang, options); ok {
+		return &Operation{Action: LicenseOk, Path: path, License: name}
+	}
+
+	if !found {
+		if options.Add {
+			newContent := insertHeader(lang, content, lang.renderLine(spdxTag+" "+expected.String()), options.spacing())
+			if err := h.WriteFile(path, []byte(newContent)); err != nil {
+				return &Operation{Action: OperationError, Path: path}
+			}
+			return &Operation{Action: LicenseAdded, Path: path}
+		}
+		return &Operation{Action: SkippedAdd, Path: path}
+	}
+
+	if options.Replace {
+		newContent := content[:start] + expected.String() + content[end:]
+		if err := h.WriteFile(path, []byte(newContent)); err != nil {
+			return &Operation{Action: OperationError, Path: path}
+		}
+		return &Operation{Action: LicenseReplaced, Path: path, Header: match}
+	}
+	return &Operation{Action: SkippedReplace, Path: path, Header: match}
+}
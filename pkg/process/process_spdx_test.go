@@ -0,0 +1,153 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseSPDX(t *testing.T) {
+	valid := map[string]string{
+		"MIT":                                  "MIT",
+		"Apache-2.0 OR MIT":                    "Apache-2.0 OR MIT",
+		"apache-2.0 or mit":                    "apache-2.0 OR mit",
+		"(MIT)":                                "MIT",
+		"MIT OR Apache-2.0 AND BSD-3-Clause":   "MIT OR Apache-2.0 AND BSD-3-Clause",
+		"(MIT OR Apache-2.0) AND BSD-3-Clause": "(MIT OR Apache-2.0) AND BSD-3-Clause",
+		"GPL-2.0-or-later WITH Classpath-exception-2.0":                        "GPL-2.0-or-later WITH Classpath-exception-2.0",
+		"GPL-2.0+ WITH Bison-exception-2.2 OR MIT":                             "GPL-2.0+ WITH Bison-exception-2.2 OR MIT",
+		"LicenseRef-Acme AND DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2": "LicenseRef-Acme AND DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2",
+	}
+	for expression, expected := range valid {
+		parsed, err := ParseSPDX(expression)
+		assert.Nil(t, err, expression)
+		assert.Equal(t, expected, parsed.String(), expression)
+	}
+
+	invalid := []string{"", "MIT OR", "AND MIT", "MIT Apache-2.0", "(MIT", "MIT)", "MIT WITH", "(MIT OR GPL-2.0) WITH Classpath-exception-2.0", "MIT/X11", "MIT Or Apache-2.0"}
+	for _, expression := range invalid {
+		_, err := ParseSPDX(expression)
+		assert.NotNil(t, err, expression)
+	}
+}
+
+func TestSPDXEqual(t *testing.T) {
+	expected, _ := ParseSPDX("Apache-2.0 OR MIT")
+	for expression, equal := range map[string]bool{
+		"Apache-2.0 OR MIT":   true,
+		"apache-2.0 or mit":   true,
+		"(Apache-2.0 OR MIT)": true,
+		"MIT OR Apache-2.0":   false,
+		"Apache-2.0 AND MIT":  false,
+		"Apache-2.0":          false,
+	} {
+		parsed, err := ParseSPDX(expression)
+		assert.Nil(t, err)
+		assert.Equal(t, equal, expected.Equal(parsed), expression)
+	}
+}
+
+func TestFile_SPDX(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	options := &Options{SPDX: "Apache-2.0 OR MIT"}
+
+	// Return LicenseOk if the expression is the same
+	op := File(fileName, "// SPDX-License-Identifier: apache-2.0 or mit\n\npackage main\n", "", options, handler)
+	assert.True(t, op == LicenseOk)
+
+	// The expression is only looked for in the leading comments
+	op = File(fileName, "package main\n\n// SPDX-License-Identifier: Apache-2.0 OR MIT\n", "", options, handler)
+	assert.True(t, op == SkippedAdd)
+
+	// Return SkippedReplace if the expression is different (or invalid) BUT options.Replace is false
+	op = File(fileName, "// SPDX-License-Identifier: MIT\n\npackage main\n", "", options, handler)
+	assert.True(t, op == SkippedReplace)
+	op = File(fileName, "// SPDX-License-Identifier: MIT OR\n\npackage main\n", "", options, handler)
+	assert.True(t, op == SkippedReplace)
+
+	// Return LicenseReplaced replacing only the expression if options.Replace is true
+	options.Replace = true
+	content := "/*\n * Copyright (c) 2020 The Author\n * SPDX-License-Identifier: MIT\n */\n\npackage main\n"
+	expected := "/*\n * Copyright (c) 2020 The Author\n * SPDX-License-Identifier: Apache-2.0 OR MIT\n */\n\npackage main\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = File(fileName, content, "", options, handler)
+	assert.True(t, op == LicenseReplaced)
+	handler.AssertExpectations(t)
+
+	// Return LicenseAdded with the line comment syntax of the language if options.Add is true
+	options.Add = true
+	expected = "// SPDX-License-Identifier: Apache-2.0 OR MIT\n\npackage main\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = File(fileName, "package main\n", "", options, handler)
+	assert.True(t, op == LicenseAdded)
+	handler.AssertExpectations(t)
+}
+
+func TestFile_SPDXBlockComments(t *testing.T) {
+	fileName := "styles.css"
+	handler := new(fileHandlerStub)
+	options := &Options{Add: true, Replace: true, SPDX: "MIT"}
+
+	op := File(fileName, "/* SPDX-License-Identifier: MIT */\nbody {}\n", "", options, handler)
+	assert.True(t, op == LicenseOk)
+
+	expected := "/* SPDX-License-Identifier: MIT */\nbody {}\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = File(fileName, "/* SPDX-License-Identifier: Apache-2.0 */\nbody {}\n", "", options, handler)
+	assert.True(t, op == LicenseReplaced)
+	handler.AssertExpectations(t)
+
+	expected = "/* SPDX-License-Identifier: MIT */\n\nbody {}\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = File(fileName, "body {}\n", "", options, handler)
+	assert.True(t, op == LicenseAdded)
+	handler.AssertExpectations(t)
+}
+
+func TestFiles_SPDX(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{
+		Extensions: []string{".go"},
+		SPDX:       "MIT",
+	}
+
+	// The license header file is not read in SPDX mode
+	handler.pathsToWalk = []string{"main.go"}
+	handler.On("WalkDir", options.Path, mock.Anything).Return(nil).Once()
+	handler.On("ReadFile", "main.go").Return([]byte("// SPDX-License-Identifier: MIT\npackage main\n"), nil).Once()
+
+	stats, err := Files(options, handler)
+	assert.Nil(t, err)
+	assert.True(t, len(stats.Files[LicenseOk]) == 1)
+	handler.AssertExpectations(t)
+
+	// Return error if the expression is not valid
+	options.SPDX = "MIT OR"
+	_, err = Files(options, handler)
+	assert.NotNil(t, err)
+}
@@ -23,16 +23,30 @@ SOFTWARE.
 
 package process
 
-// Stats is the result of processing multiple files
+// Stats is the result of processing multiple files. Licenses contains the name of the
+// allowed license found in the files that do not have the target one. Mappings contains
+// the files whose header matched each mapping. Scores contains the similarity of the headers
+// that did not match the target license. Reasons contains the reasons given by the exempted files.
+// Ignored contains the ignore file (.gitignore or .licenseignore) that excluded each path.
 type Stats struct {
 	ElapsedMs int64
 	Files     map[Action][]string
+	Licenses  map[string]string
+	Mappings  map[string][]string
+	Scores    map[string]float64
+	Reasons   map[string]string
+	Ignored   map[string]string
 }
 
-// NewStats creates a Stats struct with initialized Files
+// NewStats creates a Stats struct with initialized Files, Licenses, Mappings, Scores, Reasons and Ignored
 func NewStats() *Stats {
 	stats := new(Stats)
 	stats.Files = make(map[Action][]string)
+	stats.Licenses = make(map[string]string)
+	stats.Mappings = make(map[string][]string)
+	stats.Scores = make(map[string]float64)
+	stats.Reasons = make(map[string]string)
+	stats.Ignored = make(map[string]string)
 	stats.ElapsedMs = 0
 	return stats
 }
@@ -40,4 +54,25 @@ func NewStats() *Stats {
 // AddOperation to stats
 func (s *Stats) AddOperation(operation *Operation) {
 	s.Files[operation.Action] = append(s.Files[operation.Action], operation.Path)
+	if len(operation.License) > 0 {
+		s.Licenses[operation.Path] = operation.License
+	}
+	if len(operation.Mapping) > 0 {
+		s.Mappings[operation.Mapping] = append(s.Mappings[operation.Mapping], operation.Path)
+	}
+	if operation.Score != nil {
+		s.Scores[operation.Path] = *operation.Score
+	}
+	if len(operation.Reason) > 0 {
+		s.Reasons[operation.Path] = operation.Reason
+	}
+}
+
+// Count returns the number of files processed with any of the provided actions
+func (s *Stats) Count(actions ...Action) int {
+	total := 0
+	for _, action := range actions {
+		total += len(s.Files[action])
+	}
+	return total
 }
@@ -57,3 +57,43 @@ func TestAddOperation(t *testing.T) {
 	assert.True(t, stats.Files[LicenseOk][0] == "path3")
 	assert.True(t, stats.Files[LicenseOk][1] == "path4")
 }
+
+func TestCount(t *testing.T) {
+	stats := NewStats()
+	stats.AddOperation(&Operation{Action: SkippedAdd, Path: "path1"})
+	stats.AddOperation(&Operation{Action: SkippedReplace, Path: "path2"})
+	stats.AddOperation(&Operation{Action: LicenseOk, Path: "path3"})
+
+	assert.Equal(t, 2, stats.Count(SkippedAdd, SkippedReplace))
+	assert.Equal(t, 1, stats.Count(LicenseOk))
+	assert.Equal(t, 0, stats.Count(OperationError))
+	assert.Equal(t, 0, stats.Count())
+}
+
+func TestAddOperationWithLicense(t *testing.T) {
+	stats := NewStats()
+	stats.AddOperation(&Operation{Action: LicenseOk, Path: "path1"})
+	stats.AddOperation(&Operation{Action: LicenseOk, Path: "path2", License: "bsd.txt"})
+
+	assert.Equal(t, []string{"path1", "path2"}, stats.Files[LicenseOk])
+	assert.Equal(t, map[string]string{"path2": "bsd.txt"}, stats.Licenses)
+}
+
+func TestAddOperationWithMapping(t *testing.T) {
+	stats := NewStats()
+	stats.AddOperation(&Operation{Action: LicenseReplaced, Path: "path1", Mapping: "acme"})
+	stats.AddOperation(&Operation{Action: SkippedReplace, Path: "path2", Mapping: "acme"})
+	stats.AddOperation(&Operation{Action: LicenseReplaced, Path: "path3"})
+
+	assert.Equal(t, map[string][]string{"acme": {"path1", "path2"}}, stats.Mappings)
+}
+
+func TestAddOperationWithScore(t *testing.T) {
+	stats := NewStats()
+	score, zero := 0.8, 0.0
+	stats.AddOperation(&Operation{Action: SkippedReplace, Path: "path1", Score: &score})
+	stats.AddOperation(&Operation{Action: LicenseOk, Path: "path2"})
+	stats.AddOperation(&Operation{Action: ForeignLicense, Path: "path3", Score: &zero})
+
+	assert.Equal(t, map[string]float64{"path1": 0.8, "path3": 0}, stats.Scores)
+}
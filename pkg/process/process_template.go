@@ -0,0 +1,134 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+const (
+	// yearPattern matches a year or a range of years (e.g. 2019-2023)
+	yearPattern = `\d{4}(?:[ \t]*-[ \t]*\d{4})?`
+	// variablePattern matches the value of any variable but the year
+	variablePattern = `[^\n]*?`
+)
+
+// licenseTemplate is a license header that may contain variables such as {{year}}, {{holder}} or {{file}}
+type licenseTemplate struct {
+	tmpl    *template.Template
+	pattern *regexp.Regexp
+}
+
+// newLicenseTemplate parses the license header of the file at path.
+//
+// The variables available are year (the current year unless a value is provided in the options),
+// file (the name of the file) and any of the variables provided in the options.
+func newLicenseTemplate(path, license string, options *Options) (*licenseTemplate, error) {
+	variables := map[string]string{
+		"year": strconv.Itoa(time.Now().Year()),
+		"file": filepath.Base(path),
+	}
+	for name, value := range options.Variables {
+		variables[name] = value
+	}
+
+	funcs := template.FuncMap{}
+	for name, value := range variables {
+		value := value
+		funcs[name] = func() string { return value }
+	}
+
+	tmpl, err := template.New("license").Funcs(funcs).Parse(license)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := templatePattern(tmpl, options.UpdateYear)
+	if err != nil {
+		return nil, err
+	}
+
+	return &licenseTemplate{tmpl: tmpl, pattern: pattern}, nil
+}
+
+// execute returns the license header with the values of the variables
+func (l *licenseTemplate) execute() (string, error) {
+	var sb strings.Builder
+	if err := l.tmpl.Execute(&sb, nil); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// templatePattern returns a regular expression that matches the license header with any value
+// of its variables. If yearInsensitive is true, the years of the copyright notices of the license
+// header match any year too.
+func templatePattern(tmpl *template.Template, yearInsensitive bool) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	if tmpl.Tree == nil {
+		return regexp.Compile("")
+	}
+	for _, node := range tmpl.Tree.Root.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			if yearInsensitive {
+				sb.WriteString(quoteYearInsensitive(string(n.Text)))
+			} else {
+				sb.WriteString(regexp.QuoteMeta(string(n.Text)))
+			}
+		case *parse.ActionNode:
+			name, ok := variableName(n)
+			if !ok {
+				return nil, fmt.Errorf("unsupported template action %s, only variables such as {{year}} are supported", n)
+			}
+			if name == "year" {
+				sb.WriteString(yearPattern)
+			} else {
+				sb.WriteString(variablePattern)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported template action %s, only variables such as {{year}} are supported", n)
+		}
+	}
+	return regexp.Compile(sb.String())
+}
+
+// variableName returns the name of the variable used by an action such as {{year}}
+func variableName(n *parse.ActionNode) (string, bool) {
+	if n.Pipe == nil || len(n.Pipe.Decl) > 0 || len(n.Pipe.Cmds) != 1 || len(n.Pipe.Cmds[0].Args) != 1 {
+		return "", false
+	}
+	identifier, ok := n.Pipe.Cmds[0].Args[0].(*parse.IdentifierNode)
+	if !ok {
+		return "", false
+	}
+	return identifier.Ident, true
+}
@@ -0,0 +1,83 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLicenseTemplatePattern(t *testing.T) {
+	options := &Options{Variables: map[string]string{"holder": "Acme"}}
+	tmpl, err := newLicenseTemplate("main.go", "/* Copyright {{year}} {{holder}} */", options)
+	assert.Nil(t, err)
+
+	assert.True(t, tmpl.pattern.MatchString("/* Copyright 2019 Acme */"))
+	assert.True(t, tmpl.pattern.MatchString("/* Copyright 2024 Acme */"))
+	assert.True(t, tmpl.pattern.MatchString("/* Copyright 2019-2023 Acme */"))
+	assert.True(t, tmpl.pattern.MatchString("/* Copyright 2024 Another Holder */"))
+	assert.False(t, tmpl.pattern.MatchString("/* Copyright XXXX Acme */"))
+	assert.False(t, tmpl.pattern.MatchString("/* Copyright 2024\nAcme */"))
+
+	// Licenses without variables must match literally
+	tmpl, err = newLicenseTemplate("main.go", "/* Copyright (c) 2020 The Author. */", options)
+	assert.Nil(t, err)
+	assert.True(t, tmpl.pattern.MatchString("package main\n/* Copyright (c) 2020 The Author. */"))
+	assert.False(t, tmpl.pattern.MatchString("/* Copyright (c) 2020 The Author! */"))
+}
+
+func TestLicenseTemplateExecute(t *testing.T) {
+	options := &Options{Variables: map[string]string{"holder": "Acme"}}
+	tmpl, err := newLicenseTemplate("src/main.go", "/* {{file}}: Copyright {{year}} {{holder}} */", options)
+	assert.Nil(t, err)
+	header, err := tmpl.execute()
+	assert.Nil(t, err)
+	assert.Equal(t, "/* main.go: Copyright "+strconv.Itoa(time.Now().Year())+" Acme */", header)
+
+	// The year can be provided as a variable
+	options.Variables["year"] = "2019"
+	tmpl, err = newLicenseTemplate("src/main.go", "/* Copyright {{year}} {{holder}} */", options)
+	assert.Nil(t, err)
+	header, err = tmpl.execute()
+	assert.Nil(t, err)
+	assert.Equal(t, "/* Copyright 2019 Acme */", header)
+}
+
+func TestLicenseTemplateErrors(t *testing.T) {
+	options := &Options{}
+	_, err := newLicenseTemplate("main.go", "/* Copyright {{year}} {{holder}} */", options)
+	assert.NotNil(t, err)
+
+	_, err = newLicenseTemplate("main.go", "/* Copyright {{if year}}{{year}}{{end}} */", options)
+	assert.NotNil(t, err)
+
+	_, err = newLicenseTemplate("main.go", "/* Copyright {{year | printf \"%s\"}} */", options)
+	assert.NotNil(t, err)
+
+	_, err = newLicenseTemplate("main.go", "/* Copyright {{year */", options)
+	assert.NotNil(t, err)
+}
@@ -27,7 +27,9 @@ import (
 	"errors"
 	"io/fs"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -64,17 +66,49 @@ func (s *fileHandlerStub) ReadFile(filename string) ([]byte, error) {
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (s *fileHandlerStub) ReadPrefix(filename string, n int) ([]byte, error) {
+	args := s.Called(filename, n)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (s *fileHandlerStub) ModTime(name string) (time.Time, error) {
+	args := s.Called(name)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (s *fileHandlerStub) CommitTime(name string) (time.Time, error) {
+	args := s.Called(name)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
 func (s *fileHandlerStub) WalkDir(path string, walkDirFn fs.WalkDirFunc) error {
 	args := s.Called(path, walkDirFn)
 
 	dirEntry := &dirEntryMock{}
 	dirEntry.On("IsDir").Return(s.isDir)
+	// The paths ending with / are directories
+	dir := &dirEntryMock{}
+	dir.On("IsDir").Return(true)
+	var skippedDirs []string
+walk:
 	for _, path := range s.pathsToWalk {
 		var errSent error
 		if s.errorWalkingPath {
 			errSent = errors.New("error")
 		}
-		if err := walkDirFn(path, dirEntry, errSent); err != nil {
+		entry := dirEntry
+		if strings.HasSuffix(path, "/") {
+			path, entry = strings.TrimSuffix(path, "/"), dir
+		}
+		for _, skipped := range skippedDirs {
+			if strings.HasPrefix(path, skipped+"/") {
+				continue walk
+			}
+		}
+		err := walkDirFn(path, entry, errSent)
+		if err == fs.SkipDir && entry.IsDir() {
+			skippedDirs = append(skippedDirs, path)
+		} else if err != nil {
 			return nil
 		}
 	}
@@ -245,6 +279,31 @@ func TestFiles_ErrorSentByWalk(t *testing.T) {
 	handler.AssertExpectations(t)
 }
 
+// missingRootHandler mocks a fileHandler whose root path does not exist, which filepath.WalkDir
+// reports calling the function with the error and a nil fs.DirEntry
+type missingRootHandler struct {
+	*fileHandlerStub
+}
+
+func (h missingRootHandler) WalkDir(path string, walkDirFn fs.WalkDirFunc) error {
+	return walkDirFn(path, nil, fs.ErrNotExist)
+}
+
+func TestFiles_ErrorMissingRoot(t *testing.T) {
+	handler := missingRootHandler{new(fileHandlerStub)}
+	options := &Options{
+		LicensePath: "license.txt",
+		Path:        "missing",
+		Extensions:  []string{".cpp"},
+	}
+	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
+
+	// The error is returned instead of processing the root
+	_, err := Files(options, handler)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	handler.AssertExpectations(t)
+}
+
 func TestFiles_DoesNotCountDir(t *testing.T) {
 	options := &Options{
 		LicensePath: "license.txt",
@@ -259,9 +318,177 @@ func TestFiles_DoesNotCountDir(t *testing.T) {
 	// ReadFile should return the license
 	handler.On("ReadFile", "license.txt").Return([]byte(testTargetLicenseHeader), nil).Once()
 
+	// The directory is walked looking for a .licenseignore file
+	handler.On("ReadFile", "file_no_license.cpp/.licenseignore").Return([]byte{}, fs.ErrNotExist).Once()
+
 	stats, err := Files(options, handler)
 	assert.Nil(t, err)
 	assert.True(t, len(stats.Files[LicenseAdded]) == 0)
 
 	handler.AssertExpectations(t)
 }
+
+func TestActionNames(t *testing.T) {
+	for _, action := range []Action{SkippedAdd, SkippedReplace, LicenseOk, LicenseAdded, LicenseReplaced, OperationError, YearOutdated, YearUpdated, Misplaced, LicenseMoved, LicenseMerged, LicenseRemoved, ForeignLicense, DuplicateHeader, DuplicateFixed, SkippedGenerated, Exempted, SkippedRemove, NoHeader} {
+		parsed, err := ParseAction(action.String())
+		assert.Nil(t, err)
+		assert.Equal(t, action, parsed)
+	}
+
+	_, err := ParseAction("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestFile_LanguageCommentStyle(t *testing.T) {
+	fileName := "main.py"
+	handler := new(fileHandlerStub)
+	options := &Options{Replace: true}
+	license := "# Copyright (c) 2020 The Author\n"
+
+	expected := "#!/usr/bin/env python\n\n# Copyright (c) 2020 The Author\n\nprint('Hello')\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op := File(fileName, "#!/usr/bin/env python\n\n# Copyright (c) 2019 Someone Else\n\nprint('Hello')\n", license, options, handler)
+	assert.True(t, op == LicenseReplaced)
+	handler.AssertExpectations(t)
+}
+
+func TestFile_RenderLicense(t *testing.T) {
+	fileName := "main.py"
+	handler := new(fileHandlerStub)
+	options := &Options{Add: true}
+
+	// The target license is rendered with the comment syntax of the file
+	op := File(fileName, "# Copyright (c) 2020 The Author\n\nprint('Hello')\n", "/* Copyright (c) 2020 The Author */", options, handler)
+	assert.True(t, op == LicenseOk)
+
+	expected := "# Copyright (c) 2020 The Author\n\nprint('Hello')\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = File(fileName, "print('Hello')\n", "Copyright (c) 2020 The Author\n", options, handler)
+	assert.True(t, op == LicenseAdded)
+	handler.AssertExpectations(t)
+}
+
+func TestFile_TemplateLicense(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	options := &Options{Add: true, Replace: true, Variables: map[string]string{"holder": "Acme", "year": "2024"}}
+	license := "Copyright {{year}} {{holder}}"
+
+	// Any value of the variables is accepted
+	op := File(fileName, "/*\n * Copyright 2019 Acme\n */\n\npackage main\n", license, options, handler)
+	assert.True(t, op == LicenseOk)
+
+	// The values of the variables are used when adding the license
+	expected := "/*\n * Copyright 2024 Acme\n */\n\npackage main\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = File(fileName, "package main\n", license, options, handler)
+	assert.True(t, op == LicenseAdded)
+	handler.AssertExpectations(t)
+
+	// Return OperationError if the license is not a valid template
+	op = File(fileName, "package main\n", "Copyright {{year}} {{unknown}}", options, handler)
+	assert.True(t, op == OperationError)
+}
+
+func TestFiles_ErrorParsingLicense(t *testing.T) {
+	handler := new(fileHandlerStub)
+	options := &Options{
+		LicensePath: "license.txt",
+	}
+
+	// Return a license with an unknown variable
+	handler.On("ReadFile", "license.txt").Return([]byte("Copyright {{year}} {{holder}}"), nil).Once()
+
+	// Assert that Files will return an error
+	_, err := Files(options, handler)
+	assert.NotNil(t, err)
+
+	handler.AssertExpectations(t)
+}
+
+func TestFile_MisplacedLicense(t *testing.T) {
+	fileName := "main.js"
+	handler := new(fileHandlerStub)
+	options := &Options{}
+	content := testFileWithoutLicense + "\n" + testTargetLicenseHeader
+
+	// Return Misplaced if the license is not at the top of the file BUT options.Replace is false
+	op := File(fileName, content, testTargetLicenseHeader, options, handler)
+	assert.True(t, op == Misplaced)
+
+	// Return LicenseMoved if the license is not at the top of the file and options.Replace is true
+	options.Replace = true
+	handler.On("WriteFile", fileName, []byte(testFileWithTargetLicense)).Return(nil).Once()
+	op = File(fileName, content, testTargetLicenseHeader, options, handler)
+	assert.True(t, op == LicenseMoved)
+	handler.AssertExpectations(t)
+
+	// Licenses inside the code are not a license of the file, so it is handled as if it had none
+	content = "const license = `" + testTargetLicenseHeader + "`;\n"
+	op = File(fileName, content, testTargetLicenseHeader, &Options{}, handler)
+	assert.True(t, op == SkippedAdd)
+
+	// and they are not moved with options.Replace as that would break the code
+	handler = new(fileHandlerStub)
+	op = File(fileName, content, testTargetLicenseHeader, &Options{Replace: true}, handler)
+	assert.True(t, op == SkippedAdd)
+	handler.AssertNotCalled(t, "WriteFile", fileName, mock.Anything)
+
+	// while the license is added with options.Add
+	handler.On("WriteFile", fileName, []byte(strings.TrimSpace(testTargetLicenseHeader)+"\n\n"+content)).Return(nil).Once()
+	op = File(fileName, content, testTargetLicenseHeader, &Options{Add: true, Replace: true}, handler)
+	assert.True(t, op == LicenseAdded)
+	handler.AssertExpectations(t)
+}
+
+func TestFile_Preamble(t *testing.T) {
+	fileName := "main.py"
+	handler := new(fileHandlerStub)
+	options := &Options{Add: true, Replace: true}
+	license := "Copyright (c) 2020 The Author"
+
+	// The license is added after the shebang
+	content := "#!/usr/bin/env python\nprint('Hello')\n"
+	expected := "#!/usr/bin/env python\n\n# Copyright (c) 2020 The Author\n\nprint('Hello')\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op := File(fileName, content, license, options, handler)
+	assert.True(t, op == LicenseAdded)
+
+	// The license is replaced after the shebang and the encoding line
+	content = "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n# Copyright (c) 2019 Another Author\n\nprint('Hello')\n"
+	expected = "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n\n# Copyright (c) 2020 The Author\n\nprint('Hello')\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = File(fileName, content, license, options, handler)
+	assert.True(t, op == LicenseReplaced)
+
+	// The license after the preamble is ok
+	op = File(fileName, expected, license, options, handler)
+	assert.True(t, op == LicenseOk)
+	handler.AssertExpectations(t)
+}
+
+func TestFileOperation_Header(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	options := &Options{}
+	oldHeader := "/* Copyright (c) 2019 Another Author */"
+	content := oldHeader + "\n\npackage main\n\nconst header = `" + oldHeader + "`\n"
+
+	// The header that would be replaced is reported
+	op := FileOperation(fileName, content, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, SkippedReplace, op.Action)
+	assert.Equal(t, &HeaderMatch{Start: 0, End: len(oldHeader), Text: oldHeader}, op.Header)
+
+	// Only that header is replaced
+	options.Replace = true
+	expected := strings.TrimSpace(testTargetLicenseHeader) + "\n\npackage main\n\nconst header = `" + oldHeader + "`\n"
+	handler.On("WriteFile", fileName, []byte(expected)).Return(nil).Once()
+	op = FileOperation(fileName, content, testTargetLicenseHeader, options, handler)
+	assert.Equal(t, LicenseReplaced, op.Action)
+	handler.AssertExpectations(t)
+
+	// Files without a header do not report any
+	op = FileOperation(fileName, "package main\n", testTargetLicenseHeader, &Options{}, handler)
+	assert.Equal(t, SkippedAdd, op.Action)
+	assert.Nil(t, op.Header)
+}
@@ -0,0 +1,107 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// copyrightYearRegex matches the years of a copyright notice such as "Copyright (c) 2019-2023"
+var copyrightYearRegex = regexp.MustCompile(`(?i)(copyright(?:[ \t]+(?:\(c\)|©))?[ \t]+)(\d{4})(?:([ \t]*-[ \t]*)(\d{4}))?`)
+
+// quoteYearInsensitive returns the regular expression that matches the text literally but
+// for the years of its copyright notices, which match any year or range of years
+func quoteYearInsensitive(text string) string {
+	var sb strings.Builder
+	last := 0
+	for _, match := range copyrightYearRegex.FindAllStringSubmatchIndex(text, -1) {
+		// match[4] is the start of the first year and match[1] the end of the notice
+		sb.WriteString(regexp.QuoteMeta(text[last:match[4]]))
+		sb.WriteString(yearPattern)
+		last = match[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(text[last:]))
+	return sb.String()
+}
+
+// updateYears returns the content with the copyright notices found between start and end
+// ending in the provided year. Notices ending in that year (or later) are not changed. The
+// second return value is false if no notice had to be changed.
+func updateYears(content string, start, end, year int) (string, bool) {
+	var sb strings.Builder
+	updated := false
+	last := start
+	sb.WriteString(content[:start])
+	for _, match := range copyrightYearRegex.FindAllStringSubmatchIndex(content[start:end], -1) {
+		startYear, _ := strconv.Atoi(content[start+match[4] : start+match[5]])
+		endYear := startYear
+		if match[8] >= 0 {
+			endYear, _ = strconv.Atoi(content[start+match[8] : start+match[9]])
+		}
+		if endYear >= year || startYear > year {
+			continue
+		}
+		sb.WriteString(content[last : start+match[4]])
+		sb.WriteString(strconv.Itoa(startYear) + "-" + strconv.Itoa(year))
+		last = start + match[1]
+		updated = true
+	}
+	sb.WriteString(content[last:])
+	return sb.String(), updated
+}
+
+// lastModified returns the time of the last modification of the file. If options.YearFromGit
+// is true, the date of the last commit that modified the file is used (if there is any).
+func lastModified(path string, options *Options, h fileHandler) (time.Time, error) {
+	if options.YearFromGit {
+		if t, err := h.CommitTime(path); err == nil {
+			return t, nil
+		}
+	}
+	return h.ModTime(path)
+}
+
+// checkYear verifies that the copyright notices of the license header found between start and
+// end are not older than the last modification of the file, updating them if options.Replace is true
+func checkYear(path, content string, start, end int, options *Options, h fileHandler) Action {
+	modified, err := lastModified(path, options, h)
+	if err != nil {
+		return OperationError
+	}
+
+	newContent, outdated := updateYears(content, start, end, modified.Year())
+	if !outdated {
+		return LicenseOk
+	}
+	if !options.Replace {
+		return YearOutdated
+	}
+	if err := h.WriteFile(path, []byte(newContent)); err != nil {
+		return OperationError
+	}
+	return YearUpdated
+}
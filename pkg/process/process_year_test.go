@@ -0,0 +1,123 @@
+/* MIT License
+
+Copyright (c) 2022 Lluis Sanchez
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package process
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuoteYearInsensitive(t *testing.T) {
+	re := regexp.MustCompile(quoteYearInsensitive("/* Copyright (c) 2019-2023 The Author. */"))
+	assert.True(t, re.MatchString("/* Copyright (c) 2019-2023 The Author. */"))
+	assert.True(t, re.MatchString("/* Copyright (c) 2019 The Author. */"))
+	assert.True(t, re.MatchString("/* Copyright (c) 2020 - 2026 The Author. */"))
+	assert.False(t, re.MatchString("/* Copyright (c) 2019-2023 Another Author. */"))
+
+	re = regexp.MustCompile(quoteYearInsensitive("/* Licensed under the MIT License (2019) */"))
+	assert.False(t, re.MatchString("/* Licensed under the MIT License (2020) */"))
+}
+
+func TestUpdateYears(t *testing.T) {
+	content := "/* Copyright (c) 2019-2023 The Author */\n/* Copyright (c) 2019-2023 Another Author */"
+	header := len("/* Copyright (c) 2019-2023 The Author */")
+
+	output, updated := updateYears(content, 0, header, 2026)
+	assert.True(t, updated)
+	assert.Equal(t, "/* Copyright (c) 2019-2026 The Author */\n/* Copyright (c) 2019-2023 Another Author */", output)
+
+	output, updated = updateYears("// Copyright 2020 The Author", 0, 28, 2026)
+	assert.True(t, updated)
+	assert.Equal(t, "// Copyright 2020-2026 The Author", output)
+
+	output, updated = updateYears("# COPYRIGHT © 2019 - 2021 The Author, Copyright 2022 Others", 0, 59, 2024)
+	assert.True(t, updated)
+	assert.Equal(t, "# COPYRIGHT © 2019-2024 The Author, Copyright 2022-2024 Others", output)
+
+	_, updated = updateYears(content, 0, header, 2023)
+	assert.False(t, updated)
+
+	_, updated = updateYears("/* Copyright 2030 The Author */", 0, 31, 2026)
+	assert.False(t, updated)
+}
+
+func TestFile_UpdateYear(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	options := &Options{UpdateYear: true}
+	license := "/* Copyright (c) 2019-2023 The Author */"
+	content := "/* Copyright (c) 2019-2023 The Author */\n\npackage main\n"
+	modified := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Return YearOutdated if the year is older than the file modification BUT options.Replace is false
+	handler.On("ModTime", fileName).Return(modified, nil).Once()
+	op := File(fileName, content, license, options, handler)
+	assert.True(t, op == YearOutdated)
+	handler.AssertExpectations(t)
+
+	// Return YearUpdated if the year is older than the file modification and options.Replace is true
+	options.Replace = true
+	handler.On("ModTime", fileName).Return(modified, nil).Once()
+	handler.On("WriteFile", fileName, []byte("/* Copyright (c) 2019-2026 The Author */\n\npackage main\n")).Return(nil).Once()
+	op = File(fileName, content, license, options, handler)
+	assert.True(t, op == YearUpdated)
+	handler.AssertExpectations(t)
+
+	// Return LicenseOk if the year is up to date
+	handler.On("ModTime", fileName).Return(time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), nil).Once()
+	op = File(fileName, content, license, options, handler)
+	assert.True(t, op == LicenseOk)
+	handler.AssertExpectations(t)
+
+	// Return OperationError if the modification time is not available
+	handler.On("ModTime", fileName).Return(time.Time{}, errors.New("error")).Once()
+	op = File(fileName, content, license, options, handler)
+	assert.True(t, op == OperationError)
+	handler.AssertExpectations(t)
+}
+
+func TestFile_UpdateYearFromGit(t *testing.T) {
+	fileName := "main.go"
+	handler := new(fileHandlerStub)
+	options := &Options{UpdateYear: true, YearFromGit: true}
+	license := "/* Copyright (c) 2019-2023 The Author */"
+	content := "/* Copyright (c) 2019-2023 The Author */\n\npackage main\n"
+
+	// The date of the last commit takes precedence over the modification time
+	handler.On("CommitTime", fileName).Return(time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), nil).Once()
+	op := File(fileName, content, license, options, handler)
+	assert.True(t, op == LicenseOk)
+	handler.AssertExpectations(t)
+
+	// The modification time is used for files that have not been committed
+	handler.On("CommitTime", fileName).Return(time.Time{}, errors.New("error")).Once()
+	handler.On("ModTime", fileName).Return(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), nil).Once()
+	op = File(fileName, content, license, options, handler)
+	assert.True(t, op == YearOutdated)
+	handler.AssertExpectations(t)
+}
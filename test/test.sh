@@ -60,6 +60,29 @@ run_test() {
 	fi
 }
 
+run_exit_code_test() {
+	flags=$1
+	expected=$3
+
+	# extract sample project
+	delete_sample_project
+	extract_sample_project
+
+	# print test case
+	echo -e "\n$2"
+
+	# execute license-header-checker discarding the output
+	$CMD $flags $CMD_ARGS >/dev/null 2>&1
+	exit_code=$?
+
+	# verify result
+	if [[ "$exit_code" == "$expected" ]]; then
+		on_success
+	else
+		on_failure
+	fi
+}
+
 # Test cases
 
 # version
@@ -109,6 +132,26 @@ expected_output="\
 files: license_ok: - sample-project/src/file-with-license.js license_replaced: - sample-project/src/file-with-old-license.cpp - sample-project/test/file-with-old-license.go license_added: - sample-project/src/file-without-license.java options: project_path: sample-project ignore_paths: - src/other extensions: - .java - .js - .cpp - .go flags: - add - replace - verbose license_header: %ssample-project/licenses/current-license.txt totals: license_ok: 1 files license_replaced: 2 files license_added: 1 files elapsed_time: 0ms"
 run_test "$flags" "$test_case" "$expected_output"
 
+# exit code without check mode
+flags=''
+test_case='Testing exit code without -check flag...'
+run_exit_code_test "$flags" "$test_case" 0
+
+# exit code in check mode with files to fix
+flags='-check'
+test_case='Testing exit code with -check flag...'
+run_exit_code_test "$flags" "$test_case" 1
+
+# exit code in check mode after fixing the files
+flags='-a -r -check'
+test_case='Testing exit code with -a and -r and -check flags...'
+run_exit_code_test "$flags" "$test_case" 0
+
+# exit code in check mode with custom failing actions
+flags='-a -r -fail-on license_added'
+test_case='Testing exit code with -a and -r and -fail-on flags...'
+run_exit_code_test "$flags" "$test_case" 1
+
 delete_sample_project
 
 if (($errors > 0)); then